@@ -0,0 +1,130 @@
+// Package cache : file contains counters of failed login attempts
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+const (
+	attemptsKeyPrefix = "attempts:"
+	lockKeyPrefix     = "lock:"
+)
+
+// LoginAttempts counts failed attempts and keeps temporary locks, key is account or client address
+type LoginAttempts interface {
+	Failed(ctx context.Context, key string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+	Reset(ctx context.Context, key string) error
+}
+
+// failedScript increments counter and starts its window in one step, so counter cant be left without ttl
+var failedScript = redis.NewScript(`
+local n = redis.call("INCR", KEYS[1])
+if redis.call("PTTL", KEYS[1]) < 0 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return n`)
+
+// RedisLoginAttempts login attempts shared by all server instances
+type RedisLoginAttempts struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// NewRedisLoginAttempts create login attempts in redis, keys are namespaced by prefix
+func NewRedisLoginAttempts(rdsClient *redis.Client, prefix string) *RedisLoginAttempts {
+	return &RedisLoginAttempts{redisClient: rdsClient, prefix: prefix}
+}
+
+// Failed count failed attempt, counter is forgotten after window since first failure
+func (a *RedisLoginAttempts) Failed(ctx context.Context, key string, window time.Duration) (int64, error) {
+	return failedScript.Run(ctx, a.redisClient, []string{a.prefix + attemptsKeyPrefix + key}, window.Milliseconds()).Int64()
+}
+
+// Lock forbid attempts for ttl
+func (a *RedisLoginAttempts) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	if ttl <= 0 { // redis keeps keys without ttl forever
+		return nil
+	}
+	return a.redisClient.Set(ctx, a.prefix+lockKeyPrefix+key, 1, ttl).Err()
+}
+
+// LockedFor return how long attempts are forbidden, zero when they are allowed
+func (a *RedisLoginAttempts) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := a.redisClient.PTTL(ctx, a.prefix+lockKeyPrefix+key).Result()
+	if err != nil || ttl < 0 { // negative ttl means there is no lock
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// Reset forget failed attempts and lock
+func (a *RedisLoginAttempts) Reset(ctx context.Context, key string) error {
+	return a.redisClient.Del(ctx, a.prefix+attemptsKeyPrefix+key, a.prefix+lockKeyPrefix+key).Err()
+}
+
+// MemoryLoginAttempts login attempts of one process, used in tests
+type MemoryLoginAttempts struct {
+	mu       sync.Mutex
+	counters map[string]memoryCounter
+	locks    map[string]time.Time
+}
+
+// memoryCounter failed attempts in window
+type memoryCounter struct {
+	count   int64
+	expires time.Time
+}
+
+// NewMemoryLoginAttempts create empty in-memory login attempts
+func NewMemoryLoginAttempts() *MemoryLoginAttempts {
+	return &MemoryLoginAttempts{counters: map[string]memoryCounter{}, locks: map[string]time.Time{}}
+}
+
+// Failed count failed attempt, counter is forgotten after window since first failure
+func (a *MemoryLoginAttempts) Failed(_ context.Context, key string, window time.Duration) (int64, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	now := time.Now()
+	c, ok := a.counters[key]
+	if !ok || now.After(c.expires) {
+		c = memoryCounter{expires: now.Add(window)}
+	}
+	c.count++
+	a.counters[key] = c
+	return c.count, nil
+}
+
+// Lock forbid attempts for ttl
+func (a *MemoryLoginAttempts) Lock(_ context.Context, key string, ttl time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.locks[key] = time.Now().Add(ttl)
+	return nil
+}
+
+// LockedFor return how long attempts are forbidden, zero when they are allowed
+func (a *MemoryLoginAttempts) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	left := time.Until(a.locks[key])
+	if left <= 0 {
+		delete(a.locks, key)
+		return 0, nil
+	}
+	return left, nil
+}
+
+// Reset forget failed attempts and lock
+func (a *MemoryLoginAttempts) Reset(_ context.Context, key string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	delete(a.counters, key)
+	delete(a.locks, key)
+	return nil
+}
@@ -0,0 +1,42 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryLoginAttempts(t *testing.T) {
+	ctx := context.Background()
+	a := NewMemoryLoginAttempts()
+	for i := int64(1); i <= 3; i++ {
+		n, err := a.Failed(ctx, "account", time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, i, n)
+	}
+	n, err := a.Failed(ctx, "short", time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+	time.Sleep(5 * time.Millisecond)
+	n, err = a.Failed(ctx, "short", time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n, "counter outlived its window")
+
+	left, err := a.LockedFor(ctx, "account")
+	require.NoError(t, err)
+	require.Zero(t, left)
+	require.NoError(t, a.Lock(ctx, "account", time.Minute))
+	left, err = a.LockedFor(ctx, "account")
+	require.NoError(t, err)
+	require.Greater(t, left, 59*time.Second)
+
+	require.NoError(t, a.Reset(ctx, "account"))
+	left, err = a.LockedFor(ctx, "account")
+	require.NoError(t, err)
+	require.Zero(t, left)
+	n, err = a.Failed(ctx, "account", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n, "counter survived reset")
+}
@@ -11,45 +11,51 @@ import (
 	"github.com/labstack/gommon/log"
 )
 
+const (
+	userKeyPrefix   = "user:"
+	advertKeyPrefix = "advert:"
+)
+
 // UserCache struct for cache
 type UserCache struct {
 	redisClient *redis.Client
+	prefix      string
+	ttl         time.Duration
+}
+
+// NewCache create new redis connection, keys are namespaced by prefix and expire after ttl
+func NewCache(rdsClient *redis.Client, prefix string, ttl time.Duration) *UserCache {
+	return &UserCache{redisClient: rdsClient, prefix: prefix, ttl: ttl}
 }
 
-// NewCache create new redis connection
-func NewCache(rdsClient *redis.Client) *UserCache {
-	return &UserCache{redisClient: rdsClient}
+// key build namespaced redis key
+func (u *UserCache) key(name string) string {
+	return u.prefix + name
 }
 
-// AddToCache add user to cache
+// AddToCache add user to cache by his id
 func (u *UserCache) AddToCache(ctx context.Context, person *model.Person) error {
 	user, err := json.Marshal(person)
 	if err != nil {
 		log.Errorf("cache: failed add user to cache, %e", err)
 		return err
 	}
-	err = u.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: "user",
-		ID:     "0-*",
-		Values: map[string]interface{}{"About": user},
-	}).Err()
+	err = u.redisClient.Set(ctx, u.key(userKeyPrefix+person.ID), user, u.ttl).Err()
 	if err != nil {
 		log.Errorf("cache: failed add user to cache, %e", err)
 		return err
 	}
 	return nil
 }
+
+// AddAdvertToCache add advert to cache by its id
 func (u *UserCache) AddAdvertToCache(ctx context.Context, advert *model.Advert) error {
-	user, err := json.Marshal(advert)
+	data, err := json.Marshal(advert)
 	if err != nil {
 		log.Errorf("cache: failed add advert to cache, %e", err)
 		return err
 	}
-	err = u.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: "advert",
-		ID:     "0-*",
-		Values: map[string]interface{}{"About": user},
-	}).Err()
+	err = u.redisClient.Set(ctx, u.key(advertKeyPrefix+advert.ID), data, u.ttl).Err()
 	if err != nil {
 		log.Errorf("cache: failed add advert to cache, %e", err)
 		return err
@@ -57,13 +63,9 @@ func (u *UserCache) AddAdvertToCache(ctx context.Context, advert *model.Advert)
 	return nil
 }
 
-// GetUserByIDFromCache get user from cache
-func (u *UserCache) GetUserByIDFromCache(ctx context.Context) (model.Person, bool, error) {
-	result, err := u.redisClient.XRead(ctx, &redis.XReadArgs{
-		Streams: []string{"user", "0"},
-		Count:   1,
-		Block:   1 * time.Millisecond,
-	}).Result()
+// GetUserByIDFromCache get user from cache by his id
+func (u *UserCache) GetUserByIDFromCache(ctx context.Context, id string) (model.Person, bool, error) {
+	data, err := u.redisClient.Get(ctx, u.key(userKeyPrefix+id)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return model.Person{}, false, nil
@@ -71,11 +73,8 @@ func (u *UserCache) GetUserByIDFromCache(ctx context.Context) (model.Person, boo
 		log.Errorf("failed get user by id from cache: %e", err)
 		return model.Person{}, false, err
 	}
-	bytes := result[0].Messages[0]
-	msg := bytes.Values
-	msgString := msg["About"].(string)
 	person := model.Person{}
-	err = json.Unmarshal([]byte(msgString), &person)
+	err = json.Unmarshal(data, &person)
 	if err != nil {
 		log.Errorf("failed get user by id from cache: %e", err)
 		return model.Person{}, false, err
@@ -83,57 +82,28 @@ func (u *UserCache) GetUserByIDFromCache(ctx context.Context) (model.Person, boo
 	return person, true, nil
 }
 
-func (u *UserCache) GetAdvertByIDFromCache(ctx context.Context) (model.Advert, bool, error) {
-	result, err := u.redisClient.XRead(ctx, &redis.XReadArgs{
-		Streams: []string{"advert", "0"},
-		Count:   1,
-		Block:   1 * time.Millisecond,
-	}).Result()
+// GetAdvertByIDFromCache get advert from cache by its id
+func (u *UserCache) GetAdvertByIDFromCache(ctx context.Context, id string) (model.Advert, bool, error) {
+	data, err := u.redisClient.Get(ctx, u.key(advertKeyPrefix+id)).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return model.Advert{}, false, nil
 		}
-		log.Errorf("failed get user by id from cache: %e", err)
+		log.Errorf("failed get advert by id from cache: %e", err)
 		return model.Advert{}, false, err
 	}
-	bytes := result[0].Messages[0]
-	msg := bytes.Values
-	msgString := msg["About"].(string)
 	advert := model.Advert{}
-	err = json.Unmarshal([]byte(msgString), &advert)
+	err = json.Unmarshal(data, &advert)
 	if err != nil {
-		log.Errorf("failed get user by id from cache: %e", err)
+		log.Errorf("failed get advert by id from cache: %e", err)
 		return model.Advert{}, false, err
 	}
 	return advert, true, nil
 }
 
-// DeleteUserFromCache delete user from cache
-func (u *UserCache) DeleteUserFromCache(ctx context.Context) error {
-	_, found, err := u.GetUserByIDFromCache(ctx)
-	if err != nil {
-		return err
-	}
-	if !found {
-		return nil
-	}
-	err = u.redisClient.FlushAll(ctx).Err()
-	if err != nil {
-		log.Errorf("failed to delete user from cache, %e", err)
-		return err
-	}
-	return nil
-}
-
-func (u *UserCache) DeleteAdvertFromCache(ctx context.Context) error {
-	_, found, err := u.GetAdvertByIDFromCache(ctx)
-	if err != nil {
-		return err
-	}
-	if !found {
-		return nil
-	}
-	err = u.redisClient.FlushAll(ctx).Err()
+// DeleteUserFromCache delete user with this id from cache
+func (u *UserCache) DeleteUserFromCache(ctx context.Context, id string) error {
+	err := u.redisClient.Del(ctx, u.key(userKeyPrefix+id)).Err()
 	if err != nil {
 		log.Errorf("failed to delete user from cache, %e", err)
 		return err
@@ -141,93 +111,11 @@ func (u *UserCache) DeleteAdvertFromCache(ctx context.Context) error {
 	return nil
 }
 
-// GetAllUsersFromCache get all users from cache
-func (u *UserCache) GetAllUsersFromCache(ctx context.Context) ([]*model.Person, bool, error) {
-	result, err := u.redisClient.XRead(ctx, &redis.XReadArgs{
-		Streams: []string{"all-users", "0"},
-		Count:   1,
-		Block:   1 * time.Millisecond,
-	}).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, false, nil
-		}
-		log.Errorf("failed get user by id from cache: %e", err)
-		return nil, false, err
-	}
-	bytes := result[0].Messages[0]
-	msg := bytes.Values
-	msgString := msg["About"].(string)
-	var persons []*model.Person
-
-	err = json.Unmarshal([]byte(msgString), &persons)
-	if err != nil {
-		log.Errorf("failed to unmarshal json, %e", err)
-		return nil, false, err
-	}
-
-	return persons, true, nil
-}
-
-func (u *UserCache) GetAllAdvertsFromCache(ctx context.Context) ([]*model.Advert, bool, error) {
-	result, err := u.redisClient.XRead(ctx, &redis.XReadArgs{
-		Streams: []string{"all-adverts", "0"},
-		Count:   1,
-		Block:   1 * time.Millisecond,
-	}).Result()
-	if err != nil {
-		if err == redis.Nil {
-			return nil, false, nil
-		}
-		log.Errorf("failed get advers by id from cache: %e", err)
-		return nil, false, err
-	}
-	bytes := result[0].Messages[0]
-	msg := bytes.Values
-	msgString := msg["About"].(string)
-	var adverts []*model.Advert
-
-	err = json.Unmarshal([]byte(msgString), &adverts)
-	if err != nil {
-		log.Errorf("failed to unmarshal json, %e", err)
-		return nil, false, err
-	}
-
-	return adverts, true, nil
-}
-
-// AddAllUsersToCache add all users from db to cache
-func (u *UserCache) AddAllUsersToCache(ctx context.Context, person []*model.Person) error {
-	user, err := json.Marshal(person)
-	if err != nil {
-		log.Errorf("cache: failed add all users to cache, %e", err)
-		return err
-	}
-	err = u.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: "all-users",
-		ID:     "0-*",
-		Values: map[string]interface{}{"About": user},
-	}).Err()
-	if err != nil {
-		log.Errorf("cache: failed add all users to cache, %e", err)
-		return err
-	}
-	return nil
-}
-
-func (u *UserCache) AddAllAdvertsToCache(ctx context.Context, adverts []*model.Advert) error {
-	advert, err := json.Marshal(adverts)
-	if err != nil {
-		log.Errorf("cache: failed add all users to cache, %e", err)
-		return err
-	}
-	err = u.redisClient.XAdd(ctx, &redis.XAddArgs{
-		Stream: "all-users",
-		ID:     "0-*",
-		Values: map[string]interface{}{"About": advert},
-	}).Err()
+// DeleteAdvertFromCache delete advert with this id from cache
+func (u *UserCache) DeleteAdvertFromCache(ctx context.Context, id string) error {
+	err := u.redisClient.Del(ctx, u.key(advertKeyPrefix+id)).Err()
 	if err != nil {
-		log.Errorf("cache: failed add all users to cache, %e", err)
+		log.Errorf("failed to delete advert from cache, %e", err)
 		return err
 	}
 	return nil
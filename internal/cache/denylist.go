@@ -0,0 +1,83 @@
+// Package cache : file contains list of revoked access tokens
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+)
+
+const revokedKeyPrefix = "revoked:"
+
+// Denylist keeps revoked token ids until tokens expire by themselves
+type Denylist interface {
+	Revoke(ctx context.Context, id string, ttl time.Duration) error
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+// RedisDenylist denylist shared by all server instances
+type RedisDenylist struct {
+	redisClient *redis.Client
+	prefix      string
+}
+
+// NewRedisDenylist create denylist in redis, keys are namespaced by prefix
+func NewRedisDenylist(rdsClient *redis.Client, prefix string) *RedisDenylist {
+	return &RedisDenylist{redisClient: rdsClient, prefix: prefix}
+}
+
+// Revoke add id to denylist for ttl
+func (d *RedisDenylist) Revoke(ctx context.Context, id string, ttl time.Duration) error {
+	if ttl <= 0 { // token is already expired
+		return nil
+	}
+	return d.redisClient.Set(ctx, d.prefix+revokedKeyPrefix+id, 1, ttl).Err()
+}
+
+// IsRevoked check if id is in denylist
+func (d *RedisDenylist) IsRevoked(ctx context.Context, id string) (bool, error) {
+	n, err := d.redisClient.Exists(ctx, d.prefix+revokedKeyPrefix+id).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// MemoryDenylist denylist of one process, used in tests
+type MemoryDenylist struct {
+	mu      sync.Mutex
+	expires map[string]time.Time
+}
+
+// NewMemoryDenylist create empty in-memory denylist
+func NewMemoryDenylist() *MemoryDenylist {
+	return &MemoryDenylist{expires: map[string]time.Time{}}
+}
+
+// Revoke add id to denylist for ttl
+func (d *MemoryDenylist) Revoke(_ context.Context, id string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.expires[id] = time.Now().Add(ttl)
+	return nil
+}
+
+// IsRevoked check if id is in denylist, expired ids are forgotten
+func (d *MemoryDenylist) IsRevoked(_ context.Context, id string) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	expires, ok := d.expires[id]
+	if !ok {
+		return false, nil
+	}
+	if time.Now().After(expires) {
+		delete(d.expires, id)
+		return false, nil
+	}
+	return true, nil
+}
@@ -0,0 +1,23 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryDenylist(t *testing.T) {
+	ctx := context.Background()
+	d := NewMemoryDenylist()
+	require.NoError(t, d.Revoke(ctx, "revoked", time.Minute))
+	require.NoError(t, d.Revoke(ctx, "expired", time.Millisecond))
+	require.NoError(t, d.Revoke(ctx, "dead", -time.Minute))
+	time.Sleep(5 * time.Millisecond)
+	for id, revoked := range map[string]bool{"revoked": true, "expired": false, "dead": false, "unknown": false} {
+		ok, err := d.IsRevoked(ctx, id)
+		require.NoError(t, err)
+		require.Equal(t, revoked, ok, "wrong state of %s", id)
+	}
+}
@@ -0,0 +1,88 @@
+// Package handlers : file contains mapping of domain errors to http responses
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// ErrorHandler echo HTTPErrorHandler which maps domain errors to http status codes
+func ErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+	code, message := statusOf(err)
+	if code == http.StatusInternalServerError {
+		log.Errorf("request %s %s failed: %v", c.Request().Method, c.Path(), err)
+	}
+	response := model.ErrorResponse{Message: message}
+	var validationErr *model.ValidationError
+	if errors.As(err, &validationErr) {
+		response.Details = validationErr.Fields
+	}
+	var retryErr *model.RetryAfterError
+	if errors.As(err, &retryErr) {
+		c.Response().Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryErr.After.Seconds()))))
+	}
+	if c.Request().Method == http.MethodHead {
+		err = c.NoContent(code)
+	} else {
+		err = c.JSON(code, response)
+	}
+	if err != nil {
+		log.Errorf("failed to send error response: %v", err)
+	}
+}
+
+// validationError convert errors of request validator to ValidationError with problem of every field
+func validationError(err error) error {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return fmt.Errorf("%v: %w", err, model.ErrValidation)
+	}
+	result := &model.ValidationError{}
+	for _, f := range fieldErrs {
+		message := "must satisfy " + f.Tag()
+		if f.Param() != "" {
+			message += "=" + f.Param()
+		}
+		result.Fields = append(result.Fields, model.FieldError{Field: f.Field(), Message: message})
+	}
+	return result
+}
+
+// statusOf return http status code and client message for error
+func statusOf(err error) (code int, message string) {
+	var httpErr *echo.HTTPError
+	switch {
+	case errors.As(err, &httpErr):
+		return httpErr.Code, fmt.Sprint(httpErr.Message)
+	case errors.Is(err, model.ErrNotFound):
+		return http.StatusNotFound, err.Error()
+	case errors.Is(err, model.ErrConflict):
+		return http.StatusConflict, err.Error()
+	case errors.Is(err, model.ErrValidation):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, model.ErrUnauthorized):
+		return http.StatusUnauthorized, err.Error()
+	case errors.Is(err, model.ErrForbidden):
+		return http.StatusForbidden, err.Error()
+	case errors.Is(err, model.ErrTooManyRequests):
+		return http.StatusTooManyRequests, err.Error()
+	case errors.Is(err, model.ErrLocked):
+		return http.StatusLocked, err.Error()
+	case errors.Is(err, model.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge, err.Error()
+	case errors.Is(err, model.ErrUnsupportedMedia):
+		return http.StatusUnsupportedMediaType, err.Error()
+	}
+	return http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)
+}
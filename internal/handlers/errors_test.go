@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorHandler(t *testing.T) {
+	testData := []struct {
+		err  error
+		code int
+	}{
+		{fmt.Errorf("user with this id doesnt exist: %w", model.ErrNotFound), http.StatusNotFound},
+		{fmt.Errorf("user already exists: %w", model.ErrConflict), http.StatusConflict},
+		{fmt.Errorf("failed parse json: %w", model.ErrValidation), http.StatusBadRequest},
+		{fmt.Errorf("incorrect password: %w", model.ErrUnauthorized), http.StatusUnauthorized},
+		{fmt.Errorf("only owner can modify this advert: %w", model.ErrForbidden), http.StatusForbidden},
+		{fmt.Errorf("too many failed logins: %w", model.ErrTooManyRequests), http.StatusTooManyRequests},
+		{fmt.Errorf("account is locked: %w", model.ErrLocked), http.StatusLocked},
+		{fmt.Errorf("image is bigger than 10 bytes: %w", model.ErrTooLarge), http.StatusRequestEntityTooLarge},
+		{fmt.Errorf("text/plain isnt image: %w", model.ErrUnsupportedMedia), http.StatusUnsupportedMediaType},
+		{echo.NewHTTPError(http.StatusUnauthorized, "missing or malformed jwt"), http.StatusUnauthorized},
+		{errors.New("connection refused"), http.StatusInternalServerError},
+	}
+	e := echo.New()
+	for _, data := range testData {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		ErrorHandler(data.err, c)
+		require.Equal(t, data.code, rec.Code, "wrong status code for %v", data.err)
+		body := model.ErrorResponse{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), "error body isnt json")
+		require.NotEmpty(t, body.Message, "error message is empty")
+	}
+}
+
+func TestErrorHandler_ValidationDetails(t *testing.T) {
+	e := echo.New()
+	request := model.PasswordChange{NewPassword: "tujh2004"}
+	err := validationError(validate.Struct(request))
+	rec := httptest.NewRecorder()
+	ErrorHandler(fmt.Errorf("service: %w", err), e.NewContext(httptest.NewRequest(http.MethodPost, "/", nil), rec))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	body := model.ErrorResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), "error body isnt json")
+	require.Equal(t, []model.FieldError{{Field: "oldPassword", Message: "must satisfy required"}}, body.Details)
+}
+
+func TestErrorHandler_RetryAfter(t *testing.T) {
+	e := echo.New()
+	rec := httptest.NewRecorder()
+	err := &model.RetryAfterError{After: 1500 * time.Millisecond, Err: fmt.Errorf("account is locked: %w", model.ErrLocked)}
+	ErrorHandler(fmt.Errorf("login: %w", err), e.NewContext(httptest.NewRequest(http.MethodPost, "/login", nil), rec))
+	require.Equal(t, http.StatusLocked, rec.Code)
+	require.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
@@ -8,11 +8,22 @@ import (
 	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
 	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
 )
 
-var validate = validator.New()
+var validate = newValidator()
+
+// newValidator create validator which names fields as they are named in json
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(f reflect.StructField) string {
+		return strings.SplitN(f.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}
 
 // Handler struct
 type Handler struct {
@@ -26,47 +37,135 @@ func NewHandler(newS *service.Service) *Handler {
 
 // UpdateUser godoc
 // @Summary     UpdateUser
-// @Description UpdateUser is echo handler which delete user from cache and db
-// @Param       id  path string true "Account ID"
-// @Produce     string
+// @Description UpdateUser is echo handler which updates user in db and drops him from cache
+// @Param       id     path string       true "Account ID"
+// @Param       person body model.UserUpdateRequest true "update user"
+// @Accept      json
+// @Produce     plain
 // @Tags        User
-// @Router      /users/{id} [delete]
-// @Failure     500 string
-// @Success     200 string
+// @Router      /usersUpdate/{id} [put]
+// @Security    ApiKeyAuth
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {string} string
 func (h *Handler) UpdateUser(c echo.Context) error {
-	person := model.Person{}
+	update := model.UserUpdateRequest{}
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	err = json.NewDecoder(c.Request().Body).Decode(&person)
+	err = json.NewDecoder(c.Request().Body).Decode(&update)
 	if err != nil {
-		log.Errorf("failed parse json, %e", err)
-		return err
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(update)
+	if err != nil {
+		return validationError(err)
 	}
+	person := update.ToPerson()
 	err = h.s.UpdateUser(c.Request().Context(), id, &person)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.String(http.StatusOK, "Ok")
 }
 
+// UpdateRoles godoc
+// @Summary     UpdateRoles
+// @Description UpdateRoles is echo handler which replaces roles of user, available only for admin
+// @Param       id    path string             true "Account ID"
+// @Param       roles body model.RolesRequest true "new roles"
+// @Accept      json
+// @Tags        User
+// @Router      /users/{id}/roles [put]
+// @Security    ApiKeyAuth
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     204
+func (h *Handler) UpdateRoles(c echo.Context) error {
+	roles := model.RolesRequest{}
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	err = json.NewDecoder(c.Request().Body).Decode(&roles)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(roles)
+	if err != nil {
+		return validationError(err)
+	}
+	err = h.s.UpdateRoles(c.Request().Context(), id, roles.Roles)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// CreateAdvert godoc
+// @Summary     CreateAdvert
+// @Description CreateAdvert is echo handler which creates draft advert owned by authenticated user and returns it with new id
+// @Param       advert body model.AdvertRequest true "create advert"
+// @Accept      json
+// @Produce     json
+// @Tags        Advert
+// @Router      /adverts [post]
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     201 {object} model.AdvertResponse
+// @Security    ApiKeyAuth
+func (h *Handler) CreateAdvert(c echo.Context) error {
+	request := model.AdvertRequest{}
+	owner, err := principalFromToken(c)
+	if err != nil {
+		return err
+	}
+	err = json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(request)
+	if err != nil {
+		return validationError(err)
+	}
+	advert := request.ToAdvert(owner.ID)
+	created, err := h.s.CreateAdvert(c.Request().Context(), &advert)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, model.NewAdvertResponse(created))
+}
+
 func (h *Handler) UpdateAdvert(c echo.Context) error {
-	advert := model.Advert{}
+	request := model.AdvertRequest{}
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return err
 	}
-	err = json.NewDecoder(c.Request().Body).Decode(&advert)
+	actor, err := principalFromToken(c)
 	if err != nil {
-		log.Errorf("failed parse json, %e", err)
 		return err
 	}
-	err = h.s.UpdateAdvert(c.Request().Context(), id, &advert)
+	err = json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(request)
+	if err != nil {
+		return validationError(err)
+	}
+	advert := request.ToAdvert(actor.ID)
+	err = h.s.UpdateAdvert(c.Request().Context(), actor, id, &advert)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, err.Error())
+		return err
 	}
 	return c.String(http.StatusOK, "Ok")
 }
@@ -75,20 +174,22 @@ func (h *Handler) UpdateAdvert(c echo.Context) error {
 // @Summary     DeleteUser
 // @Description DeleteUser is echo handler which delete user from cache and db
 // @Param       id path string true "Account ID"
-// @Produce     string
+// @Produce     plain
 // @Tags        User
-// @Router      /users/{id} [delete]
-// @Failure     500 json
-// @Success     200 string
+// @Router      /usersDelete/{id} [delete]
+// @Security    ApiKeyAuth
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {string} string
 func (h *Handler) DeleteUser(c echo.Context) error {
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 	err = h.s.DeleteUser(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 	return c.String(http.StatusOK, "delete")
 }
@@ -97,37 +198,134 @@ func (h *Handler) DeleteAdvert(c echo.Context) error {
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	err = h.s.DeleteAdvert(c.Request().Context(), id)
+	actor, err := principalFromToken(c)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
+	}
+	err = h.s.DeleteAdvert(c.Request().Context(), actor, id)
+	if err != nil {
+		return err
 	}
 	return c.String(http.StatusOK, "delete")
 }
 
 // GetAllUsers godoc
 // @Summary     GetAllUsers
-// @Description GetAllUsers is echo handler which returns json structure of Users objects
+// @Description GetAllUsers is echo handler which returns page of Users objects, next page is requested with returned cursor
 // @Produce     json
 // @Tags        User
+// @Param       limit  query int    false "page size, 1-100" default(20)
+// @Param       offset query int    false "number of users to skip, ignored with cursor"
+// @Param       cursor query string false "nextCursor of previous page"
+// @Param       sort   query string false "sort field" Enums(name, created) default(created)
+// @Param       order  query string false "sort order" Enums(asc, desc) default(asc)
 // @Router      /users [get]
-// @Failure     500 json
-// @Success     200 json
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {object} model.UsersPageResponse
+// @Security    ApiKeyAuth
 func (h *Handler) GetAllUsers(c echo.Context) error {
-	p, err := h.s.SelectAllUsers(c.Request().Context())
+	params, err := listParams(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.s.ListUsers(c.Request().Context(), params)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return c.JSON(http.StatusOK, p)
+	return c.JSON(http.StatusOK, model.NewUsersPageResponse(page))
 }
 
+// GetAllAdvert godoc
+// @Summary     GetAllAdvert
+// @Description GetAllAdvert is echo handler which returns page of adverts matched by filter, next page is requested with returned cursor
+// @Produce     json
+// @Tags        Advert
+// @Param       limit    query int    false "page size, 1-100" default(20)
+// @Param       offset   query int    false "number of adverts to skip, ignored with cursor"
+// @Param       cursor   query string false "nextCursor of previous page"
+// @Param       sort     query string false "sort field" Enums(title, price, created) default(created)
+// @Param       order    query string false "sort order" Enums(asc, desc) default(asc)
+// @Param       minPrice query number false "lowest price"
+// @Param       maxPrice query number false "highest price"
+// @Param       address  query string false "part of address, case insensitive"
+// @Router      /adverts [get]
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {object} model.AdvertsPageResponse
 func (h *Handler) GetAllAdvert(c echo.Context) error {
-	p, err := h.s.SelectAllAdverts(c.Request().Context())
+	params, err := listParams(c)
+	if err != nil {
+		return err
+	}
+	filter, err := advertFilter(c)
+	if err != nil {
+		return err
+	}
+	page, err := h.s.ListAdverts(c.Request().Context(), params, filter)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewAdvertsPageResponse(page))
+}
+
+// SearchAdverts godoc
+// @Summary     SearchAdverts
+// @Description SearchAdverts is echo handler which returns adverts with any word of query, best matches first, matched words are in <mark> tags
+// @Produce     json
+// @Tags        Advert
+// @Param       q     query string true  "words to search"
+// @Param       limit query int    false "number of results, 1-100" default(20)
+// @Router      /adverts/search [get]
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {array} model.AdvertHitResponse
+func (h *Handler) SearchAdverts(c echo.Context) error {
+	limit := model.DefaultPageLimit
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 || n > model.MaxPageLimit {
+			return &model.ValidationError{Fields: []model.FieldError{{Field: "limit", Message: "must be integer from 1 to 100"}}}
+		}
+		limit = n
+	}
+	hits, err := h.s.SearchAdverts(c.Request().Context(), c.QueryParam("q"), limit)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewAdvertHitResponses(hits))
+}
+
+// GetUserAdverts godoc
+// @Summary     GetUserAdverts
+// @Description GetUserAdverts is echo handler which returns json structure of adverts created by user
+// @Produce     json
+// @Tags        Advert
+// @Param       id path string true "Account ID"
+// @Success     200 {array} model.AdvertResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Router      /users/{id}/adverts [get]
+// @Security    ApiKeyAuth
+func (h *Handler) GetUserAdverts(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	viewer, err := viewerFromToken(c)
+	if err != nil {
+		return err
+	}
+	adverts, err := h.s.SelectAdvertsByOwner(c.Request().Context(), viewer, id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return c.JSON(http.StatusOK, p)
+	return c.JSON(http.StatusOK, model.NewAdvertResponses(adverts))
 }
 
 // GetUserByID godoc
@@ -136,41 +334,58 @@ func (h *Handler) GetAllAdvert(c echo.Context) error {
 // @Produce     json
 // @Tags        User
 // @Param       id path string true "Account ID"
-// @Success     200 json
-// @Failure     500 json
+// @Success     200 {object} model.UserResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
 // @Router      /users/{id} [get]
 // @Security    ApiKeyAuth
 func (h *Handler) GetUserByID(c echo.Context) error {
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
 	person, err := h.s.GetUserByID(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return c.JSON(http.StatusOK, person)
+	return c.JSON(http.StatusOK, model.NewUserResponse(person))
 }
 
+// GetAdvertByID godoc
+// @Summary     GetAdvertByID
+// @Description GetAdvertByID returns advert, not published advert is found only for its owner and admins
+// @Produce     json
+// @Tags        Advert
+// @Param       id path string true "Advert ID"
+// @Success     200 {object} model.AdvertResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Router      /adverts/{id} [get]
+// @Security    ApiKeyAuth
 func (h *Handler) GetAdvertByID(c echo.Context) error {
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
+	}
+	viewer, err := viewerFromToken(c)
+	if err != nil {
+		return err
 	}
-	person, err := h.s.GetAdvertByID(c.Request().Context(), id)
+	advert, err := h.s.GetAdvertByID(c.Request().Context(), viewer, id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, err)
+		return err
 	}
-	return c.JSON(http.StatusOK, person)
+	return c.JSON(http.StatusOK, model.NewAdvertResponse(advert))
 }
 
 // ValidateValueID validate id
 func ValidateValueID(id string) error {
 	err := validate.Var(id, "required")
 	if err != nil {
-		return fmt.Errorf("id length couldnt be less then 36,~%v", err)
+		return fmt.Errorf("id length couldnt be less then 36,~%v: %w", err, model.ErrValidation)
 	}
 	return nil
 }
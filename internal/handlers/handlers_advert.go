@@ -0,0 +1,89 @@
+// Package handlers : file contains advert lifecycle and nearby search requests
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// PublishAdvert godoc
+// @Summary     PublishAdvert
+// @Description PublishAdvert is echo handler which makes draft or archived advert visible in lists and search
+// @Param       id path string true "Advert ID"
+// @Produce     json
+// @Tags        Advert
+// @Router      /adverts/{id}/publish [post]
+// @Security    ApiKeyAuth
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     409 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {object} model.AdvertResponse
+func (h *Handler) PublishAdvert(c echo.Context) error {
+	return h.moveAdvert(c, h.s.PublishAdvert)
+}
+
+// ArchiveAdvert godoc
+// @Summary     ArchiveAdvert
+// @Description ArchiveAdvert is echo handler which hides draft or published advert from lists and search
+// @Param       id path string true "Advert ID"
+// @Produce     json
+// @Tags        Advert
+// @Router      /adverts/{id}/archive [post]
+// @Security    ApiKeyAuth
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     409 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {object} model.AdvertResponse
+func (h *Handler) ArchiveAdvert(c echo.Context) error {
+	return h.moveAdvert(c, h.s.ArchiveAdvert)
+}
+
+// moveAdvert change status of advert from path by actor from token
+func (h *Handler) moveAdvert(c echo.Context, move func(ctx context.Context, actor model.Principal, id string) (model.Advert, error)) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	actor, err := principalFromToken(c)
+	if err != nil {
+		return err
+	}
+	advert, err := move(c.Request().Context(), actor, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewAdvertResponse(advert))
+}
+
+// NearbyAdverts godoc
+// @Summary     NearbyAdverts
+// @Description NearbyAdverts is echo handler which returns published adverts not farther than radius from point, nearest first
+// @Produce     json
+// @Tags        Advert
+// @Param       lat    query number true  "latitude in degrees"
+// @Param       lon    query number true  "longitude in degrees"
+// @Param       radius query number false "radius in meters, up to 200000" default(5000)
+// @Param       limit  query int    false "number of results, 1-100" default(20)
+// @Router      /adverts/nearby [get]
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {array} model.AdvertDistanceResponse
+func (h *Handler) NearbyAdverts(c echo.Context) error {
+	params, err := nearbyParams(c)
+	if err != nil {
+		return err
+	}
+	found, err := h.s.NearbyAdverts(c.Request().Context(), params)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewAdvertDistanceResponses(found))
+}
@@ -0,0 +1,186 @@
+// Package handlers : file contains advert image requests
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UploadAdvertImage godoc
+// @Summary     UploadAdvertImage
+// @Description UploadAdvertImage is echo handler which attaches jpeg, png or gif image to advert of authenticated user, type is found by content
+// @Param       id    path     string true "Advert ID"
+// @Param       image formData file   true "image file"
+// @Accept      multipart/form-data
+// @Produce     json
+// @Tags        Advert
+// @Router      /adverts/{id}/images [post]
+// @Security    ApiKeyAuth
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     409 {object} model.ErrorResponse
+// @Failure     413 {object} model.ErrorResponse
+// @Failure     415 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     201 {object} model.ImageResponse
+func (h *Handler) UploadAdvertImage(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	actor, err := principalFromToken(c)
+	if err != nil {
+		return err
+	}
+	header, err := c.FormFile("image")
+	if err != nil {
+		if errors.Is(err, echo.ErrStatusRequestEntityTooLarge) {
+			return err
+		}
+		return fmt.Errorf("failed to read image from form, %v: %w", err, model.ErrValidation)
+	}
+	file, err := header.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open uploaded image, %v", err)
+	}
+	defer file.Close()
+	image, err := h.s.AddAdvertImage(c.Request().Context(), actor, id, file)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusCreated, model.NewImageResponse(image))
+}
+
+// GetAdvertImages godoc
+// @Summary     GetAdvertImages
+// @Description GetAdvertImages is echo handler which returns images attached to advert, oldest first
+// @Param       id path string true "Advert ID"
+// @Produce     json
+// @Tags        Advert
+// @Router      /adverts/{id}/images [get]
+// @Security    ApiKeyAuth
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {array} model.ImageResponse
+func (h *Handler) GetAdvertImages(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	viewer, err := viewerFromToken(c)
+	if err != nil {
+		return err
+	}
+	attached, err := h.s.AdvertImages(c.Request().Context(), viewer, id)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, model.NewImageResponses(attached))
+}
+
+// GetAdvertImage godoc
+// @Summary     GetAdvertImage
+// @Description GetAdvertImage is echo handler which returns file of advert image
+// @Param       id      path string true "Advert ID"
+// @Param       imageId path string true "Image ID"
+// @Produce     image/jpeg,image/png,image/gif
+// @Tags        Advert
+// @Router      /adverts/{id}/images/{imageId} [get]
+// @Security    ApiKeyAuth
+// @Param       If-None-Match header string false "ETag of cached image"
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {file} file
+// @Success     304
+func (h *Handler) GetAdvertImage(c echo.Context) error {
+	return h.serveImage(c, false)
+}
+
+// GetAdvertImageThumbnail godoc
+// @Summary     GetAdvertImageThumbnail
+// @Description GetAdvertImageThumbnail is echo handler which returns jpeg thumbnail of advert image
+// @Param       id      path string true "Advert ID"
+// @Param       imageId path string true "Image ID"
+// @Produce     image/jpeg
+// @Tags        Advert
+// @Router      /adverts/{id}/images/{imageId}/thumbnail [get]
+// @Security    ApiKeyAuth
+// @Param       If-None-Match header string false "ETag of cached thumbnail"
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Success     200 {file} file
+// @Success     304
+func (h *Handler) GetAdvertImageThumbnail(c echo.Context) error {
+	return h.serveImage(c, true)
+}
+
+// serveImage stream file or thumbnail of image from path, cached copy is confirmed only after visibility check
+func (h *Handler) serveImage(c echo.Context, thumbnail bool) error {
+	advertID, imageID := c.Param("id"), c.Param("imageId")
+	err := ValidateValueID(advertID)
+	if err != nil {
+		return err
+	}
+	err = ValidateValueID(imageID)
+	if err != nil {
+		return err
+	}
+	viewer, err := viewerFromToken(c)
+	if err != nil {
+		return err
+	}
+	file, err := h.s.OpenAdvertImage(c.Request().Context(), viewer, advertID, imageID, thumbnail)
+	if err != nil {
+		return err
+	}
+	defer file.Body.Close()
+	header := c.Response().Header()
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Cache-Control", imageCacheControl(file.Public))
+	etag := imageETag(imageID, thumbnail)
+	header.Set("ETag", etag)
+	if etagMatches(c.Request().Header.Get("If-None-Match"), etag) {
+		return c.NoContent(http.StatusNotModified)
+	}
+	return c.Stream(http.StatusOK, file.ContentType, file.Body)
+}
+
+// imageCacheControl caches must revalidate image on every use, so it stops being served when advert is hidden
+// or deleted, files of not published advert are cached only by viewer
+func imageCacheControl(public bool) string {
+	if public {
+		return "public, no-cache"
+	}
+	return "private, no-cache"
+}
+
+// imageETag files never change as upload gets new id, so id identifies content
+func imageETag(imageID string, thumbnail bool) string {
+	if thumbnail {
+		return `"` + imageID + `-thumbnail"`
+	}
+	return `"` + imageID + `"`
+}
+
+// etagMatches check if If-None-Match header contains etag
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageCacheControl(t *testing.T) {
+	require.Contains(t, imageCacheControl(true), "no-cache", "image of published advert isnt revalidated")
+	require.NotContains(t, imageCacheControl(true), "immutable")
+	require.NotContains(t, imageCacheControl(false), "public", "image of draft is kept by shared caches")
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := imageETag("a20fc586-d9d2-4969-909f-d00bf42aa88a", false)
+	require.NotEqual(t, etag, imageETag("a20fc586-d9d2-4969-909f-d00bf42aa88a", true), "thumbnail has etag of image")
+	require.True(t, etagMatches(etag, etag))
+	require.True(t, etagMatches(`"other", W/`+etag, etag))
+	require.True(t, etagMatches("*", etag))
+	require.False(t, etagMatches("", etag))
+	require.False(t, etagMatches(`"other"`, etag))
+}
@@ -3,108 +3,320 @@ package handlers
 
 import (
 	"awesomeProject/internal/model"
+	"awesomeProject/internal/service"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/golang-jwt/jwt"
 	"github.com/labstack/echo/v4"
-	"github.com/labstack/gommon/log"
 )
 
 // Registration godoc
 // @Summary Registration
 // @Tags    auth
-// @Param   person body model.Person true "create user"
+// @Param   person body model.RegistrationRequest true "create user"
+// @Accept  json
 // @Produce json
-// @Success 200 string
-// @Failure 500 string
+// @Success 201 {object} model.RegistrationResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 409 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
 // @Router  /sign-up [post]
 func (h *Handler) Registration(c echo.Context) error {
-	person := model.Person{}
+	request := model.RegistrationRequest{}
 
-	err := json.NewDecoder(c.Request().Body).Decode(&person)
+	err := json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(request)
 	if err != nil {
-		log.Errorf("failed parse json, %e", err)
-		return c.String(http.StatusInternalServerError, fmt.Sprintf("%e", err))
+		return validationError(err)
 	}
+	person := request.ToPerson()
 	newID, err := h.s.Registration(c.Request().Context(), &person)
 	if err != nil {
-		return c.String(http.StatusInternalServerError, fmt.Sprintf("%e", err))
+		return err
 	}
-	return c.String(http.StatusOK, fmt.Sprintf("You register with "+`{"ID":%v}`, newID))
+	return c.JSON(http.StatusCreated, model.RegistrationResponse{ID: newID})
 }
 
 // Authentication godoc
 // @Summary Authentication
 // @Tags    auth
-// @Param   id    path string       true "Account ID"
-// @Param   login body model.Person true "user password & id"
+// @Param   login body model.Authentication true "user name & password"
 // @Produce json
 // @Accept  json
-// @Success 200 string
-// @Failure 500 string
-// @Router  /login/{id} [post]
+// @Success 200 {object} model.TokenResponse
+// @Failure 400 {object} model.ErrorResponse
+// @Failure 401 {object} model.ErrorResponse
+// @Failure 423 {object} model.ErrorResponse
+// @Failure 429 {object} model.ErrorResponse
+// @Failure 500 {object} model.ErrorResponse
+// @Router  /login [post]
 func (h *Handler) Authentication(c echo.Context) error {
 	auth := model.Authentication{}
-	id := c.Param("id")
-	err := ValidateValueID(id)
+	err := json.NewDecoder(c.Request().Body).Decode(&auth)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, "id cant be empty")
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
 	}
-	err = json.NewDecoder(c.Request().Body).Decode(&auth)
+	err = validate.Struct(auth)
 	if err != nil {
-		log.Errorf("failed parse json, %e", err)
-		return c.JSON(http.StatusInternalServerError, fmt.Errorf("error with authentication: %e", err))
+		return validationError(err)
 	}
-	accessToken, refreshToken, err := h.s.Authentication(c.Request().Context(), id, auth.Password)
+	tokens, err := h.s.Authentication(c.Request().Context(), auth.Name, auth.Password, clientInfo(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, fmt.Errorf("error with authentication: %e", err))
+		return fmt.Errorf("error with authentication: %w", err)
 	}
-	return c.String(http.StatusOK, fmt.Sprintf("You_entry_with "+`{"refreshToken":%v,"accessToken" : %v}`, refreshToken, accessToken))
+	return c.JSON(http.StatusOK, tokens)
 }
 
-// RefreshToken refresh tokens
+// RefreshToken godoc
+// @Summary  RefreshToken
+// @Tags     auth
+// @Param    refresh body model.RefreshTokens true "refresh token"
+// @Accept   json
+// @Produce  json
+// @Success  200 {object} model.TokenResponse
+// @Failure  400 {object} model.ErrorResponse
+// @Failure  401 {object} model.ErrorResponse
+// @Failure  500 {object} model.ErrorResponse
+// @Router   /refreshToken [get]
 func (h *Handler) RefreshToken(c echo.Context) error {
 	refreshToken := model.RefreshTokens{}
 	err := json.NewDecoder(c.Request().Body).Decode(&refreshToken)
 	if err != nil {
-		log.Errorf("failed parse json, %e", err)
-		return err
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
 	}
-	newAccessTokenString, newRefreshTokenString, err := h.s.RefreshToken(c.Request().Context(), refreshToken.RefreshToken)
+	tokens, err := h.s.RefreshToken(c.Request().Context(), refreshToken.RefreshToken, clientInfo(c))
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, fmt.Errorf("error while creating tokens, %e", err))
+		return fmt.Errorf("error while creating tokens: %w", err)
 	}
-	return c.JSONBlob(
-		http.StatusOK,
-		[]byte(
-			fmt.Sprintf("Tokens_refresh: "+`{
-			"accessToken" : %v,
-			"refreshToken" : %v}`, newAccessTokenString, newRefreshTokenString),
-		),
-	)
+	return c.JSON(http.StatusOK, tokens)
+}
+
+// JWKS godoc
+// @Summary JWKS
+// @Tags    auth
+// @Produce json
+// @Success 200 {object} model.JWKS
+// @Router  /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c echo.Context) error {
+	return c.JSON(http.StatusOK, h.s.JWKS())
 }
 
 // Logout godoc
 // @Summary  Logout
 // @Tags     auth
 // @Param    id path string true "Account ID"
-// @Accept   string
+// @Accept   plain
 // @Security ApiKeyAuth
 // @Router   /logout/{id} [post]
+// @Failure  403 {object} model.ErrorResponse
 func (h *Handler) Logout(c echo.Context) error {
 	id := c.Param("id")
 	err := ValidateValueID(id)
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, "id cant be empty")
+		return err
 	}
-	err = h.s.DeleteFromCache(c.Request().Context())
+	err = h.s.DeleteFromCache(c.Request().Context(), id)
 	if err != nil {
-		return c.JSON(http.StatusInternalServerError, fmt.Errorf("failed delete user from cache, %e", err))
+		return fmt.Errorf("failed delete user from cache: %w", err)
 	}
-	err = h.s.UpdateUserAuth(c.Request().Context(), id, "")
+	err = h.s.Logout(c.Request().Context(), id, accessTokenInfo(c))
 	if err != nil {
-		return c.JSON(http.StatusBadRequest, err)
+		return err
 	}
 	return c.String(http.StatusOK, "logout")
 }
+
+// GetSessions godoc
+// @Summary  GetSessions
+// @Tags     auth
+// @Param    id path string true "Account ID"
+// @Produce  json
+// @Success  200 {array}  model.Session
+// @Failure  400 {object} model.ErrorResponse
+// @Failure  401 {object} model.ErrorResponse
+// @Failure  403 {object} model.ErrorResponse
+// @Failure  500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router   /users/{id}/sessions [get]
+func (h *Handler) GetSessions(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	err = checkAccountOwner(c, id)
+	if err != nil {
+		return err
+	}
+	sessions, err := h.s.Sessions(c.Request().Context(), id, accessTokenInfo(c).SessionID)
+	if err != nil {
+		return err
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+// DeleteSession godoc
+// @Summary  DeleteSession
+// @Tags     auth
+// @Param    id  path string true "Account ID"
+// @Param    sid path string true "Session ID"
+// @Success  204
+// @Failure  400 {object} model.ErrorResponse
+// @Failure  401 {object} model.ErrorResponse
+// @Failure  404 {object} model.ErrorResponse
+// @Failure  403 {object} model.ErrorResponse
+// @Failure  500 {object} model.ErrorResponse
+// @Security ApiKeyAuth
+// @Router   /users/{id}/sessions/{sid} [delete]
+func (h *Handler) DeleteSession(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	sid := c.Param("sid")
+	err = ValidateValueID(sid)
+	if err != nil {
+		return err
+	}
+	err = checkAccountOwner(c, id)
+	if err != nil {
+		return err
+	}
+	err = h.s.RevokeSession(c.Request().Context(), id, sid)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// DeleteSessions godoc
+// @Summary     DeleteSessions
+// @Description DeleteSessions log out user on every device
+// @Tags        auth
+// @Param       id path string true "Account ID"
+// @Success     204
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Security    ApiKeyAuth
+// @Router      /users/{id}/sessions [delete]
+func (h *Handler) DeleteSessions(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	err = checkAccountOwner(c, id)
+	if err != nil {
+		return err
+	}
+	err = h.s.DeleteFromCache(c.Request().Context(), id)
+	if err != nil {
+		return fmt.Errorf("failed delete user from cache: %w", err)
+	}
+	err = h.s.LogoutEverywhere(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// UnlockAccount godoc
+// @Summary     UnlockAccount
+// @Description UnlockAccount forgets failed logins of user and removes his lockout, available only for admin
+// @Tags        auth
+// @Param       id path string true "Account ID"
+// @Success     204
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     404 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Security    ApiKeyAuth
+// @Router      /users/{id}/lock [delete]
+func (h *Handler) UnlockAccount(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	err = h.s.UnlockAccount(c.Request().Context(), id)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// clientInfo describe device which opens session
+func clientInfo(c echo.Context) model.ClientInfo {
+	return model.ClientInfo{UserAgent: c.Request().UserAgent(), IP: c.RealIP()}
+}
+
+// principalFromToken take authenticated user from access token claims
+func principalFromToken(c echo.Context) (model.Principal, error) {
+	claims, err := claimsFromToken(c)
+	if err != nil {
+		return model.Principal{}, err
+	}
+	return service.PrincipalFromClaims(claims)
+}
+
+// checkAccountOwner allow sessions of account only to its owner, routes check it too but sessions must not leak by route mistake
+func checkAccountOwner(c echo.Context, id string) error {
+	principal, err := principalFromToken(c)
+	if err != nil {
+		return err
+	}
+	if principal.ID != id {
+		return fmt.Errorf("only owner can manage sessions of this account: %w", model.ErrForbidden)
+	}
+	return nil
+}
+
+// viewerFromToken take user from access token of request which may be anonymous, nil means anonymous
+func viewerFromToken(c echo.Context) (*model.Principal, error) {
+	if c.Get("user") == nil {
+		return nil, nil
+	}
+	principal, err := principalFromToken(c)
+	if err != nil {
+		return nil, err
+	}
+	return &principal, nil
+}
+
+// accessTokenInfo take identity of access token from its claims, empty for tokens without it
+func accessTokenInfo(c echo.Context) model.AccessTokenInfo {
+	claims, err := claimsFromToken(c)
+	if err != nil {
+		return model.AccessTokenInfo{}
+	}
+	info := model.AccessTokenInfo{}
+	info.ID, _ = claims["jti"].(string)
+	info.SessionID, _ = claims["sid"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		info.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return info
+}
+
+// claimsFromToken take claims of access token checked by auth middleware
+func claimsFromToken(c echo.Context) (jwt.MapClaims, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, fmt.Errorf("access token is missing: %w", model.ErrUnauthorized)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse access token claims: %w", model.ErrUnauthorized)
+	}
+	return claims, nil
+}
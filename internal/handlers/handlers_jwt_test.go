@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClaimsFromToken(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	_, err := principalFromToken(c)
+	require.Error(t, err, "user id without token")
+	viewer, err := viewerFromToken(c)
+	require.NoError(t, err, "anonymous viewer is error")
+	require.Nil(t, viewer, "viewer without token")
+	require.Empty(t, accessTokenInfo(c), "token info without token")
+
+	c.Set("user", &jwt.Token{Claims: jwt.MapClaims{
+		"sub": "user", "roles": []interface{}{model.RoleUser}, "sid": "session", "jti": "token", "exp": float64(100)}, Valid: true})
+	principal, err := principalFromToken(c)
+	require.NoError(t, err, "cannot take user")
+	require.Equal(t, model.Principal{ID: "user", Roles: []string{model.RoleUser}}, principal)
+	viewer, err = viewerFromToken(c)
+	require.NoError(t, err, "cannot take viewer")
+	require.Equal(t, &principal, viewer)
+	require.Equal(t, model.AccessTokenInfo{ID: "token", SessionID: "session", ExpiresAt: time.Unix(100, 0)}, accessTokenInfo(c))
+}
+
+func TestSessionsOnlyForOwner(t *testing.T) {
+	const owner, other = "a20fc586-d9d2-4969-909f-d00bf42aa88a", "1fc29d3c-d5b0-4b0a-9e79-1c9bb1bb2a3d"
+	h := NewHandler(nil) // service isnt reached by foreign user
+	e := echo.New()
+	for _, handler := range []echo.HandlerFunc{h.GetSessions, h.DeleteSession, h.DeleteSessions} {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("id", "sid")
+		c.SetParamValues(owner, "session")
+		c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"sub": other}, Valid: true})
+		err := handler(c)
+		require.True(t, errors.Is(err, model.ErrForbidden), "unexpected error %v", err)
+	}
+}
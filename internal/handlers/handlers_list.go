@@ -0,0 +1,121 @@
+// Package handlers : file contains list query parameters of requests
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// listParams read page of list from query: limit, offset, cursor, sort and order=asc|desc
+func listParams(c echo.Context) (model.ListParams, error) {
+	params := model.ListParams{
+		Limit:  model.DefaultPageLimit,
+		Cursor: c.QueryParam("cursor"),
+		Sort:   c.QueryParam("sort"),
+	}
+	problems := &model.ValidationError{}
+	parseInt := func(name string, dst *int) {
+		value := c.QueryParam(name)
+		if value == "" {
+			return
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems.Fields = append(problems.Fields, model.FieldError{Field: name, Message: "must be integer"})
+			return
+		}
+		*dst = n
+	}
+	parseInt("limit", &params.Limit)
+	parseInt("offset", &params.Offset)
+	switch c.QueryParam("order") {
+	case "", "asc":
+	case "desc":
+		params.Desc = true
+	default:
+		problems.Fields = append(problems.Fields, model.FieldError{Field: "order", Message: "must satisfy oneof=asc desc"})
+	}
+	if len(problems.Fields) != 0 {
+		return model.ListParams{}, problems
+	}
+	if err := validate.Struct(params); err != nil {
+		return model.ListParams{}, validationError(err)
+	}
+	return params, nil
+}
+
+// advertFilter read advert list conditions from query: minPrice, maxPrice, address, category and currency,
+// only published adverts are listed
+func advertFilter(c echo.Context) (model.AdvertFilter, error) {
+	filter := model.AdvertFilter{
+		Address:  c.QueryParam("address"),
+		Category: c.QueryParam("category"),
+		Currency: c.QueryParam("currency"),
+		Status:   model.AdvertPublished,
+	}
+	problems := &model.ValidationError{}
+	parsePrice := func(name string) *model.Price {
+		value := c.QueryParam(name)
+		if value == "" {
+			return nil
+		}
+		price, err := model.ParsePrice(value)
+		if err != nil {
+			problems.Fields = append(problems.Fields, model.FieldError{Field: name, Message: "must be number with at most 2 fraction digits"})
+			return nil
+		}
+		return &price
+	}
+	filter.MinPrice = parsePrice("minPrice")
+	filter.MaxPrice = parsePrice("maxPrice")
+	if len(problems.Fields) != 0 {
+		return model.AdvertFilter{}, problems
+	}
+	if err := validate.Struct(filter); err != nil {
+		return model.AdvertFilter{}, validationError(err)
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return model.AdvertFilter{}, &model.ValidationError{Fields: []model.FieldError{{Field: "maxPrice", Message: "must be not less than minPrice"}}}
+	}
+	return filter, nil
+}
+
+// nearbyParams read circle of nearby search from query: lat, lon, radius in meters and limit
+func nearbyParams(c echo.Context) (model.NearbyParams, error) {
+	params := model.NearbyParams{Radius: model.DefaultNearbyRadius, Limit: model.DefaultPageLimit}
+	problems := &model.ValidationError{}
+	parseFloat := func(name string, dst *float64, required bool) {
+		value := c.QueryParam(name)
+		if value == "" {
+			if required {
+				problems.Fields = append(problems.Fields, model.FieldError{Field: name, Message: "must satisfy required"})
+			}
+			return
+		}
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			problems.Fields = append(problems.Fields, model.FieldError{Field: name, Message: "must be number"})
+			return
+		}
+		*dst = n
+	}
+	parseFloat("lat", &params.Lat, true)
+	parseFloat("lon", &params.Lon, true)
+	parseFloat("radius", &params.Radius, false)
+	if value := c.QueryParam("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			problems.Fields = append(problems.Fields, model.FieldError{Field: "limit", Message: "must be integer"})
+		}
+		params.Limit = n
+	}
+	if len(problems.Fields) != 0 {
+		return model.NearbyParams{}, problems
+	}
+	if err := validate.Struct(params); err != nil {
+		return model.NearbyParams{}, validationError(err)
+	}
+	return params, nil
+}
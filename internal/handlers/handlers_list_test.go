@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListParams(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts?limit=5&sort=price&order=desc&cursor=abc", nil), httptest.NewRecorder())
+	params, err := listParams(c)
+	require.NoError(t, err)
+	require.Equal(t, model.ListParams{Limit: 5, Cursor: "abc", Sort: model.SortPrice, Desc: true}, params)
+
+	c = e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts", nil), httptest.NewRecorder())
+	params, err = listParams(c)
+	require.NoError(t, err)
+	require.Equal(t, model.DefaultPageLimit, params.Limit)
+
+	for _, query := range []string{"limit=0", "limit=101", "limit=ten", "offset=-1", "order=up"} {
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts?"+query, nil), httptest.NewRecorder())
+		_, err = listParams(c)
+		require.True(t, errors.Is(err, model.ErrValidation), "%s must be rejected", query)
+	}
+}
+
+func TestAdvertFilter(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts?minPrice=10&maxPrice=20.5&address=minsk&category=flats&currency=EUR", nil), httptest.NewRecorder())
+	filter, err := advertFilter(c)
+	require.NoError(t, err)
+	require.Equal(t, model.Price(1000), *filter.MinPrice)
+	require.Equal(t, model.Price(2050), *filter.MaxPrice)
+	require.Equal(t, "minsk", filter.Address)
+	require.Equal(t, "flats", filter.Category)
+	require.Equal(t, "EUR", filter.Currency)
+	require.Equal(t, model.AdvertPublished, filter.Status, "only published adverts are listed")
+
+	for _, query := range []string{"minPrice=-1", "maxPrice=cheap", "maxPrice=1.001", "minPrice=30&maxPrice=20", "currency=dollar"} {
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts?"+query, nil), httptest.NewRecorder())
+		_, err = advertFilter(c)
+		require.True(t, errors.Is(err, model.ErrValidation), "%s must be rejected", query)
+	}
+}
+
+func TestNearbyParams(t *testing.T) {
+	e := echo.New()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts/nearby?lat=53.9&lon=27.56", nil), httptest.NewRecorder())
+	params, err := nearbyParams(c)
+	require.NoError(t, err)
+	require.Equal(t, model.NearbyParams{Location: model.Location{Lat: 53.9, Lon: 27.56},
+		Radius: model.DefaultNearbyRadius, Limit: model.DefaultPageLimit}, params)
+
+	for _, query := range []string{"lon=27", "lat=53", "lat=91&lon=0", "lat=0&lon=-181", "lat=0&lon=0&radius=0",
+		"lat=0&lon=0&radius=far", "lat=0&lon=0&radius=300000", "lat=0&lon=0&limit=0"} {
+		c = e.NewContext(httptest.NewRequest(http.MethodGet, "/adverts/nearby?"+query, nil), httptest.NewRecorder())
+		_, err = nearbyParams(c)
+		require.True(t, errors.Is(err, model.ErrValidation), "%s must be rejected", query)
+	}
+}
@@ -0,0 +1,102 @@
+// Package handlers : file contains password change and reset requests
+package handlers
+
+import (
+	"awesomeProject/internal/model"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ChangePassword godoc
+// @Summary     ChangePassword
+// @Description ChangePassword replaces password of user after checking the old one and closes all his sessions
+// @Tags        auth
+// @Param       id       path string               true "Account ID"
+// @Param       password body model.PasswordChange true "old and new passwords"
+// @Accept      json
+// @Success     204
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     403 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Security    ApiKeyAuth
+// @Router      /users/{id}/password [post]
+func (h *Handler) ChangePassword(c echo.Context) error {
+	id := c.Param("id")
+	err := ValidateValueID(id)
+	if err != nil {
+		return err
+	}
+	change := model.PasswordChange{}
+	err = json.NewDecoder(c.Request().Body).Decode(&change)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(change)
+	if err != nil {
+		return validationError(err)
+	}
+	err = h.s.ChangePassword(c.Request().Context(), id, change.OldPassword, change.NewPassword)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
+
+// RequestPasswordReset godoc
+// @Summary     RequestPasswordReset
+// @Description RequestPasswordReset sends reset token to user, response is the same for unknown users
+// @Tags        auth
+// @Param       request body model.PasswordResetRequest true "user name"
+// @Accept      json
+// @Success     202
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Router      /password-reset [post]
+func (h *Handler) RequestPasswordReset(c echo.Context) error {
+	request := model.PasswordResetRequest{}
+	err := json.NewDecoder(c.Request().Body).Decode(&request)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(request)
+	if err != nil {
+		return validationError(err)
+	}
+	err = h.s.RequestPasswordReset(c.Request().Context(), request.Name)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusAccepted)
+}
+
+// ResetPassword godoc
+// @Summary     ResetPassword
+// @Description ResetPassword sets new password by reset token and closes all sessions of user
+// @Tags        auth
+// @Param       confirm body model.PasswordResetConfirm true "reset token and new password"
+// @Accept      json
+// @Success     204
+// @Failure     400 {object} model.ErrorResponse
+// @Failure     401 {object} model.ErrorResponse
+// @Failure     500 {object} model.ErrorResponse
+// @Router      /password-reset/confirm [post]
+func (h *Handler) ResetPassword(c echo.Context) error {
+	confirm := model.PasswordResetConfirm{}
+	err := json.NewDecoder(c.Request().Body).Decode(&confirm)
+	if err != nil {
+		return fmt.Errorf("failed parse json, %v: %w", err, model.ErrValidation)
+	}
+	err = validate.Struct(confirm)
+	if err != nil {
+		return validationError(err)
+	}
+	err = h.s.ResetPassword(c.Request().Context(), confirm.Token, confirm.NewPassword)
+	if err != nil {
+		return err
+	}
+	return c.NoContent(http.StatusNoContent)
+}
@@ -0,0 +1,85 @@
+// Package images : file contains checking of uploaded images and thumbnails
+package images
+
+import (
+	"awesomeProject/internal/model"
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif" // decoders of accepted formats
+	"image/jpeg"
+	_ "image/png"
+	"net/http"
+
+	"golang.org/x/image/draw"
+)
+
+// ThumbnailContentType thumbnails are always jpeg
+const ThumbnailContentType = "image/jpeg"
+
+// formats accepted content types with names of their decoders
+var formats = map[string]string{
+	"image/jpeg": "jpeg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+// Processed uploaded image with its checked type, size and thumbnail
+type Processed struct {
+	ContentType string
+	Width       int
+	Height      int
+	Thumbnail   []byte
+}
+
+// Process check that data is image of accepted type by its content, not by name or header of client,
+// and make thumbnail of it
+func Process(data []byte, cfg model.ImageConfig) (Processed, error) {
+	contentType := http.DetectContentType(data)
+	format, ok := formats[contentType]
+	if !ok {
+		return Processed{}, fmt.Errorf("%s isnt jpeg, png or gif image: %w", contentType, model.ErrUnsupportedMedia)
+	}
+	config, decoded, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || decoded != format {
+		return Processed{}, fmt.Errorf("image is broken, %v: %w", err, model.ErrUnsupportedMedia)
+	}
+	if config.Width*config.Height > cfg.MaxPixels { // checked before decoding so huge image cant exhaust memory
+		return Processed{}, fmt.Errorf("image %dx%d has more than %d pixels: %w", config.Width, config.Height, cfg.MaxPixels, model.ErrTooLarge)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return Processed{}, fmt.Errorf("image is broken, %v: %w", err, model.ErrUnsupportedMedia)
+	}
+	thumbnail := bytes.Buffer{}
+	err = jpeg.Encode(&thumbnail, Thumbnail(img, cfg.ThumbnailSize), &jpeg.Options{Quality: 85})
+	if err != nil {
+		return Processed{}, fmt.Errorf("failed to encode thumbnail, %v", err)
+	}
+	return Processed{ContentType: contentType, Width: config.Width, Height: config.Height, Thumbnail: thumbnail.Bytes()}, nil
+}
+
+// Thumbnail scale image down to fit in square with side size keeping proportions, transparent parts become white,
+// small images are not enlarged
+func Thumbnail(src image.Image, size int) *image.NRGBA {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > size || h > size {
+		if w >= h {
+			tw, th = size, h*size/w
+		} else {
+			tw, th = w*size/h, size
+		}
+	}
+	if tw < 1 {
+		tw = 1
+	}
+	if th < 1 {
+		th = 1
+	}
+	dst := image.NewNRGBA(image.Rect(0, 0, tw, th))
+	draw.Draw(dst, dst.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, b, draw.Over, nil)
+	return dst
+}
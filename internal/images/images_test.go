@@ -0,0 +1,76 @@
+package images
+
+import (
+	"awesomeProject/internal/model"
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = model.ImageConfig{MaxSize: 1 << 20, MaxPixels: 1000 * 1000, MaxPerAdvert: 10, ThumbnailSize: 32}
+
+func testPNG(t *testing.T, w, h int, c color.Color) []byte {
+	img := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+	buf := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestProcess(t *testing.T) {
+	processed, err := Process(testPNG(t, 200, 100, color.NRGBA{R: 255, A: 255}), testConfig)
+	require.NoError(t, err)
+	require.Equal(t, "image/png", processed.ContentType)
+	require.Equal(t, 200, processed.Width)
+	require.Equal(t, 100, processed.Height)
+	thumbnail, err := jpeg.Decode(bytes.NewReader(processed.Thumbnail))
+	require.NoError(t, err)
+	require.Equal(t, image.Rect(0, 0, 32, 16), thumbnail.Bounds())
+	r, g, _, _ := thumbnail.At(10, 10).RGBA()
+	require.Greater(t, r, uint32(0xf000))
+	require.Less(t, g, uint32(0x1000))
+}
+
+func TestProcess_Rejected(t *testing.T) {
+	_, err := Process([]byte("<html><script>alert(1)</script></html>"), testConfig)
+	require.True(t, errors.Is(err, model.ErrUnsupportedMedia))
+
+	broken := testPNG(t, 10, 10, color.Black)[:60]
+	_, err = Process(broken, testConfig)
+	require.True(t, errors.Is(err, model.ErrUnsupportedMedia))
+
+	_, err = Process(testPNG(t, 1001, 1000, color.Black), testConfig)
+	require.True(t, errors.Is(err, model.ErrTooLarge))
+}
+
+func TestThumbnail(t *testing.T) {
+	require.Equal(t, image.Rect(0, 0, 10, 5), Thumbnail(image.NewRGBA(image.Rect(0, 0, 10, 5)), 32).Bounds(), "small image is not enlarged")
+	require.Equal(t, image.Rect(0, 0, 1, 32), Thumbnail(image.NewRGBA(image.Rect(0, 0, 10, 1000)), 32).Bounds())
+
+	transparent := Thumbnail(image.NewNRGBA(image.Rect(0, 0, 4, 4)), 2)
+	require.Equal(t, color.NRGBA{R: 255, G: 255, B: 255, A: 255}, transparent.NRGBAAt(0, 0), "transparent becomes white")
+
+	paletted := image.NewPaletted(image.Rect(0, 0, 40, 40), color.Palette{color.Black, color.NRGBA{R: 255, A: 255}})
+	for i := range paletted.Pix {
+		paletted.Pix[i] = 1
+	}
+	red := Thumbnail(paletted, 10)
+	require.Equal(t, color.NRGBA{R: 255, A: 255}, red.NRGBAAt(5, 5), "gif colors are changed")
+}
+
+func BenchmarkThumbnail(b *testing.B) {
+	img := image.NewYCbCr(image.Rect(0, 0, 4000, 3000), image.YCbCrSubsampleRatio420)
+	for i := 0; i < b.N; i++ {
+		Thumbnail(img, 320)
+	}
+}
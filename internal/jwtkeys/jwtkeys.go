@@ -0,0 +1,190 @@
+// Package jwtkeys : file contains keys for signing and checking jwt tokens with rotation
+package jwtkeys
+
+import (
+	"awesomeProject/internal/model"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// supported signing algorithms
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// Key one key for signing and checking tokens
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	Private   interface{} // nil for keys which can only check tokens
+	Public    interface{}
+	NotBefore time.Time // key is used for signing starting from this time
+}
+
+// KeySet keys ordered by activation time, the newest active key signs tokens, all keys check them
+type KeySet struct {
+	keys []Key
+}
+
+// New create key set from jwt settings
+func New(cfg model.JWTConfig) (*KeySet, error) {
+	switch cfg.Algorithm {
+	case AlgHS256, "":
+		if cfg.Secret == "" {
+			return nil, fmt.Errorf("jwtkeys: JWT_SECRET is required for %s", AlgHS256)
+		}
+		secret := []byte(cfg.Secret)
+		return &KeySet{keys: []Key{{Method: jwt.SigningMethodHS256, Private: secret, Public: secret}}}, nil
+	case AlgRS256, AlgEdDSA:
+		if len(cfg.Keys) == 0 {
+			return nil, fmt.Errorf("jwtkeys: JWT_KEYS is required for %s", cfg.Algorithm)
+		}
+		keys := make([]Key, 0, len(cfg.Keys))
+		for _, spec := range cfg.Keys {
+			key, err := loadKey(cfg.Algorithm, spec)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, key)
+		}
+		return NewKeySet(keys...)
+	}
+	return nil, fmt.Errorf("jwtkeys: unsupported algorithm %q", cfg.Algorithm)
+}
+
+// NewKeySet create key set from already loaded keys
+func NewKeySet(keys ...Key) (*KeySet, error) {
+	ids := map[string]bool{}
+	for _, key := range keys {
+		if key.ID == "" {
+			return nil, fmt.Errorf("jwtkeys: key id couldnt be empty")
+		}
+		if ids[key.ID] {
+			return nil, fmt.Errorf("jwtkeys: duplicate key id %q", key.ID)
+		}
+		ids[key.ID] = true
+	}
+	sorted := append([]Key(nil), keys...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].NotBefore.Before(sorted[j].NotBefore) })
+	return &KeySet{keys: sorted}, nil
+}
+
+// loadKey parse key spec "<kid>=<pem file>[@<RFC3339 activation time>]"
+func loadKey(alg, spec string) (Key, error) {
+	eq := strings.Index(spec, "=")
+	if eq <= 0 {
+		return Key{}, fmt.Errorf("jwtkeys: bad key spec %q, expected <kid>=<pem file>[@<RFC3339 time>]", spec)
+	}
+	key := Key{ID: spec[:eq]}
+	file := spec[eq+1:]
+	if at := strings.LastIndex(file, "@"); at >= 0 {
+		notBefore, err := time.Parse(time.RFC3339, file[at+1:])
+		if err != nil {
+			return Key{}, fmt.Errorf("jwtkeys: bad activation time of key %q, %v", key.ID, err)
+		}
+		key.NotBefore = notBefore
+		file = file[:at]
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return Key{}, fmt.Errorf("jwtkeys: failed to read key %q, %v", key.ID, err)
+	}
+	switch alg {
+	case AlgRS256:
+		key.Method = jwt.SigningMethodRS256
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(data); err == nil {
+			key.Private, key.Public = private, &private.PublicKey
+			return key, nil
+		}
+		public, err := jwt.ParseRSAPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("jwtkeys: key %q isnt RSA pem, %v", key.ID, err)
+		}
+		key.Public = public
+	case AlgEdDSA:
+		key.Method = jwt.SigningMethodEdDSA
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(data); err == nil {
+			edPrivate := private.(ed25519.PrivateKey)
+			key.Private, key.Public = edPrivate, edPrivate.Public()
+			return key, nil
+		}
+		public, err := jwt.ParseEdPublicKeyFromPEM(data)
+		if err != nil {
+			return Key{}, fmt.Errorf("jwtkeys: key %q isnt Ed25519 pem, %v", key.ID, err)
+		}
+		key.Public = public
+	}
+	return key, nil
+}
+
+// Current return the newest key which is already active and can sign tokens
+func (k *KeySet) Current(now time.Time) (Key, error) {
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		key := k.keys[i]
+		if key.Private != nil && !key.NotBefore.After(now) {
+			return key, nil
+		}
+	}
+	return Key{}, fmt.Errorf("jwtkeys: there is no active signing key")
+}
+
+// Sign sign claims with current key and put its id into token header
+func (k *KeySet) Sign(claims jwt.Claims, now time.Time) (string, error) {
+	key, err := k.Current(now)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.Private)
+}
+
+// Keyfunc choose key for checking token by its kid header, for jwt.Parse
+func (k *KeySet) Keyfunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	for _, key := range k.keys {
+		if key.ID != kid {
+			continue
+		}
+		if key.Method.Alg() != t.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		return key.Public, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// JWKS return public keys in JSON Web Key Set format, shared secrets are never published
+func (k *KeySet) JWKS() model.JWKS {
+	set := model.JWKS{Keys: []model.JWK{}}
+	for _, key := range k.keys {
+		jwk := model.JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Method.Alg()}
+		switch public := key.Public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
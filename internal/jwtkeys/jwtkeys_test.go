@@ -0,0 +1,97 @@
+package jwtkeys
+
+import (
+	"awesomeProject/internal/model"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/stretchr/testify/require"
+)
+
+func writePEM(t *testing.T, name, blockType string, der []byte) string {
+	file := filepath.Join(t.TempDir(), name)
+	err := os.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600)
+	require.NoError(t, err, "cannot write pem file")
+	return file
+}
+
+func TestRS256Rotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	oldFile := writePEM(t, "old.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(oldKey))
+	newFile := writePEM(t, "new.pem", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(newKey))
+	rotation := time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	keys, err := New(model.JWTConfig{
+		Algorithm: AlgRS256,
+		Keys:      []string{"new=" + newFile + "@" + rotation.Format(time.RFC3339), "old=" + oldFile},
+	})
+	require.NoError(t, err, "cannot load keys")
+
+	current, err := keys.Current(rotation.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, "old", current.ID, "new key is used before its activation")
+	current, err = keys.Current(rotation)
+	require.NoError(t, err)
+	require.Equal(t, "new", current.ID, "old key is used after rotation")
+
+	oldToken, err := keys.Sign(jwt.MapClaims{"sub": "1"}, rotation.Add(-time.Hour))
+	require.NoError(t, err)
+	_, err = jwt.Parse(oldToken, keys.Keyfunc)
+	require.NoError(t, err, "token signed by previous key must be still valid")
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 2, "both public keys must be published")
+	require.Equal(t, "RSA", jwks.Keys[0].KeyType)
+}
+
+func TestEdDSA(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	require.NoError(t, err)
+	keys, err := New(model.JWTConfig{
+		Algorithm: AlgEdDSA,
+		Keys:      []string{"ed=" + writePEM(t, "ed.pem", "PRIVATE KEY", der)},
+	})
+	require.NoError(t, err, "cannot load keys")
+
+	token, err := keys.Sign(jwt.MapClaims{"sub": "1"}, time.Now())
+	require.NoError(t, err)
+	parsed, err := jwt.Parse(token, keys.Keyfunc)
+	require.NoError(t, err)
+	require.Equal(t, "ed", parsed.Header["kid"])
+
+	jwks := keys.JWKS()
+	require.Len(t, jwks.Keys, 1)
+	require.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	require.Equal(t, "Ed25519", jwks.Keys[0].Curve)
+}
+
+func TestHS256(t *testing.T) {
+	_, err := New(model.JWTConfig{Algorithm: AlgHS256})
+	require.Error(t, err, "secret must be required")
+	keys, err := New(model.JWTConfig{Algorithm: AlgHS256, Secret: "secret"})
+	require.NoError(t, err)
+
+	token, err := keys.Sign(jwt.MapClaims{"sub": "1"}, time.Now())
+	require.NoError(t, err)
+	_, err = jwt.Parse(token, keys.Keyfunc)
+	require.NoError(t, err)
+	require.Empty(t, keys.JWKS().Keys, "shared secret must not be published")
+
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{"sub": "1"}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	_, err = jwt.Parse(forged, keys.Keyfunc)
+	require.Error(t, err, "token signed by another method must be rejected")
+}
@@ -2,12 +2,116 @@
 package middleware
 
 import (
+	"awesomeProject/internal/cache"
+	"awesomeProject/internal/jwtkeys"
+	"awesomeProject/internal/model"
 	"awesomeProject/internal/service"
+	"fmt"
 
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
-// IsAuthenticated check for authenticated
-var IsAuthenticated = middleware.JWTWithConfig(middleware.JWTConfig{
-	SigningKey: service.JwtKey,
-})
+// IsAuthenticated create check for authenticated user with not revoked tokens signed by one of keys
+func IsAuthenticated(cfg model.JWTConfig, keys *jwtkeys.KeySet, denylist cache.Denylist) echo.MiddlewareFunc {
+	checkToken := middleware.JWTWithConfig(middleware.JWTConfig{
+		ParseTokenFunc: func(auth string, c echo.Context) (interface{}, error) {
+			claims, err := service.ParseToken(cfg, keys, auth, service.AccessTokenKind)
+			if err != nil {
+				return nil, err
+			}
+			return &jwt.Token{Raw: auth, Claims: claims, Valid: true}, nil
+		},
+	})
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return checkToken(NotRevoked(denylist)(next))
+	}
+}
+
+// MaybeAuthenticated check access token like IsAuthenticated when request has it, anonymous requests pass
+func MaybeAuthenticated(cfg model.JWTConfig, keys *jwtkeys.KeySet, denylist cache.Denylist) echo.MiddlewareFunc {
+	isAuthenticated := IsAuthenticated(cfg, keys, denylist)
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		authenticated := isAuthenticated(next)
+		return func(c echo.Context) error {
+			if c.Request().Header.Get(echo.HeaderAuthorization) == "" {
+				return next(c)
+			}
+			return authenticated(c)
+		}
+	}
+}
+
+// NotRevoked reject requests with access token from denylist, must run after jwt middleware
+func NotRevoked(denylist cache.Denylist) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := tokenClaims(c)
+			if err != nil {
+				return err
+			}
+			revoked, err := service.IsTokenRevoked(c.Request().Context(), denylist, claims)
+			if err != nil {
+				return fmt.Errorf("failed to check access token: %w", err)
+			}
+			if revoked {
+				return fmt.Errorf("access token was revoked: %w", model.ErrUnauthorized)
+			}
+			return next(c)
+		}
+	}
+}
+
+// IsAccountOwner allow request only when subject of access token is user from path parameter, must run after jwt middleware
+func IsAccountOwner(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := tokenClaims(c)
+			if err != nil {
+				return err
+			}
+			principal, err := service.PrincipalFromClaims(claims)
+			if err != nil {
+				return err
+			}
+			if principal.ID != c.Param(param) {
+				return fmt.Errorf("only owner can modify this account: %w", model.ErrForbidden)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RequireRole allow request only for users with role, must run after jwt middleware
+func RequireRole(role string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			claims, err := tokenClaims(c)
+			if err != nil {
+				return err
+			}
+			principal, err := service.PrincipalFromClaims(claims)
+			if err != nil {
+				return err
+			}
+			if !principal.HasRole(role) {
+				return fmt.Errorf("%s role is required: %w", role, model.ErrForbidden)
+			}
+			return next(c)
+		}
+	}
+}
+
+// tokenClaims take claims of access token checked by jwt middleware
+func tokenClaims(c echo.Context) (jwt.MapClaims, error) {
+	token, ok := c.Get("user").(*jwt.Token)
+	if !ok {
+		return nil, fmt.Errorf("access token is missing: %w", model.ErrUnauthorized)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse access token claims: %w", model.ErrUnauthorized)
+	}
+	return claims, nil
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"awesomeProject/internal/cache"
+	"awesomeProject/internal/jwtkeys"
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNotRevoked(t *testing.T) {
+	denylist := cache.NewMemoryDenylist()
+	require.NoError(t, denylist.Revoke(context.Background(), "jti:revoked", time.Minute))
+	require.NoError(t, denylist.Revoke(context.Background(), "sid:closed", time.Minute))
+	testData := []struct {
+		claims jwt.MapClaims
+		err    error
+	}{
+		{jwt.MapClaims{"jti": "fresh", "sid": "open"}, nil},
+		{jwt.MapClaims{"jti": "revoked", "sid": "open"}, model.ErrUnauthorized},
+		{jwt.MapClaims{"jti": "fresh", "sid": "closed"}, model.ErrUnauthorized},
+	}
+	e := echo.New()
+	h := NotRevoked(denylist)(func(c echo.Context) error { return nil })
+	for _, data := range testData {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("user", &jwt.Token{Claims: data.claims, Valid: true})
+		err := h(c)
+		require.True(t, errors.Is(err, data.err), "unexpected error %v for %v", err, data.claims)
+	}
+}
+
+func TestIsAccountOwner(t *testing.T) {
+	testData := []struct {
+		sub string
+		id  string
+		err error
+	}{
+		{"a20fc586-d9d2-4969-909f-d00bf42aa88a", "a20fc586-d9d2-4969-909f-d00bf42aa88a", nil},
+		{"a20fc586-d9d2-4969-909f-d00bf42aa88a", "1fc29d3c-d5b0-4b0a-9e79-1c9bb1bb2a3d", model.ErrForbidden},
+		{"", "", model.ErrUnauthorized},
+	}
+	e := echo.New()
+	h := IsAccountOwner("id")(func(c echo.Context) error { return nil })
+	for _, data := range testData {
+		c := e.NewContext(httptest.NewRequest(http.MethodPut, "/", nil), httptest.NewRecorder())
+		c.SetParamNames("id")
+		c.SetParamValues(data.id)
+		c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"sub": data.sub}, Valid: true})
+		err := h(c)
+		require.True(t, errors.Is(err, data.err), "unexpected error %v for %s", err, data.id)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	testData := []struct {
+		roles interface{}
+		err   error
+	}{
+		{[]interface{}{model.RoleUser, model.RoleAdmin}, nil},
+		{[]interface{}{model.RoleUser}, model.ErrForbidden},
+		{nil, model.ErrForbidden},
+	}
+	e := echo.New()
+	h := RequireRole(model.RoleAdmin)(func(c echo.Context) error { return nil })
+	for _, data := range testData {
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+		c.Set("user", &jwt.Token{Claims: jwt.MapClaims{"sub": "user", "roles": data.roles}, Valid: true})
+		err := h(c)
+		require.True(t, errors.Is(err, data.err), "unexpected error %v for %v", err, data.roles)
+	}
+}
+
+func TestMaybeAuthenticated(t *testing.T) {
+	cfg := model.JWTConfig{Secret: "test-key", Issuer: "crud-server", Audience: "crud-server"}
+	keys, err := jwtkeys.New(cfg)
+	require.NoError(t, err)
+	e := echo.New()
+	h := MaybeAuthenticated(cfg, keys, cache.NewMemoryDenylist())(func(c echo.Context) error {
+		require.Nil(t, c.Get("user"), "anonymous request got user")
+		return nil
+	})
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), httptest.NewRecorder())
+	require.NoError(t, h(c), "anonymous request is rejected")
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAuthorization, "Bearer not-a-token")
+	c = e.NewContext(req, httptest.NewRecorder())
+	require.Error(t, h(c), "request with bad token passes")
+}
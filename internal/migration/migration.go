@@ -0,0 +1,252 @@
+// Package migration : file contains versioned schema migrations for PostgresDB
+package migration
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"github.com/labstack/gommon/log"
+)
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// lockID key of postgres advisory lock held while migrations are read or applied, so replicas started together
+// migrate one after another
+const lockID int64 = 4727301906
+
+const createVersionsTable = `create table if not exists schema_migrations
+(
+    version    bigint primary key,
+    name       text        not null,
+    applied_at timestamptz not null default now()
+)`
+
+// Migration one schema version with its up and down scripts
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status state of one migration in db
+type Status struct {
+	Version   int64
+	Name      string
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Migrator apply embedded migrations to PostgresDB
+type Migrator struct {
+	pool       *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator create migrator with all embedded migrations
+func NewMigrator(pool *pgxpool.Pool) (*Migrator, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{pool: pool, migrations: migrations}, nil
+}
+
+// load read migrations from embedded files named <version>_<name>.<up|down>.sql
+func load() ([]Migration, error) {
+	entries, err := sqlFiles.ReadDir("sql")
+	if err != nil {
+		return nil, fmt.Errorf("migration: failed to read migrations, %v", err)
+	}
+	byVersion := map[int64]*Migration{}
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base := strings.TrimSuffix(fileName, ".sql")
+		dot := strings.LastIndex(base, ".")
+		underscore := strings.Index(base, "_")
+		if dot < 0 || underscore < 0 || underscore > dot {
+			return nil, fmt.Errorf("migration: bad file name %s", fileName)
+		}
+		version, err := strconv.ParseInt(base[:underscore], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration: bad version in file name %s, %v", fileName, err)
+		}
+		body, err := sqlFiles.ReadFile(path.Join("sql", fileName))
+		if err != nil {
+			return nil, fmt.Errorf("migration: failed to read %s, %v", fileName, err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[underscore+1 : dot]}
+			byVersion[version] = m
+		}
+		switch base[dot+1:] {
+		case "up":
+			m.Up = string(body)
+		case "down":
+			m.Down = string(body)
+		default:
+			return nil, fmt.Errorf("migration: unknown direction in file name %s", fileName)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration: version %d must have both up and down scripts", m.Version)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// locked run fn on one connection which holds migration lock
+func (m *Migrator) locked(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("migration: failed to acquire connection, %v", err)
+	}
+	defer conn.Release()
+	_, err = conn.Exec(ctx, "select pg_advisory_lock($1)", lockID)
+	if err != nil {
+		return fmt.Errorf("migration: failed to take lock, %v", err)
+	}
+	defer func() {
+		_, err := conn.Exec(context.Background(), "select pg_advisory_unlock($1)", lockID)
+		if err != nil {
+			log.Errorf("migration: failed to release lock, %v", err)
+			_ = conn.Conn().Close(context.Background()) // lock is released with closed session
+		}
+	}()
+	return fn(conn)
+}
+
+// applied return applied versions with time of applying
+func (m *Migrator) applied(ctx context.Context, conn *pgxpool.Conn) (map[int64]time.Time, error) {
+	_, err := conn.Exec(ctx, createVersionsTable)
+	if err != nil {
+		return nil, fmt.Errorf("migration: failed to create versions table, %v", err)
+	}
+	rows, err := conn.Query(ctx, "select version,applied_at from schema_migrations")
+	if err != nil {
+		return nil, fmt.Errorf("migration: failed to select applied versions, %v", err)
+	}
+	defer rows.Close()
+	versions := map[int64]time.Time{}
+	for rows.Next() {
+		var (
+			version   int64
+			appliedAt time.Time
+		)
+		err = rows.Scan(&version, &appliedAt)
+		if err != nil {
+			return nil, fmt.Errorf("migration: failed to scan applied version, %v", err)
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+// Up apply all not applied migrations in version order
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+			err = run(ctx, conn, migration.Up, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "insert into schema_migrations(version,name) values($1,$2)", migration.Version, migration.Name)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration: failed to apply version %d, %v", migration.Version, err)
+			}
+			log.Infof("migration: applied version %d %s", migration.Version, migration.Name)
+		}
+		return nil
+	})
+}
+
+// Down roll back the last applied migration
+func (m *Migrator) Down(ctx context.Context) error {
+	return m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			migration := m.migrations[i]
+			if _, ok := applied[migration.Version]; !ok {
+				continue
+			}
+			err = run(ctx, conn, migration.Down, func(tx pgx.Tx) error {
+				_, err := tx.Exec(ctx, "delete from schema_migrations where version=$1", migration.Version)
+				return err
+			})
+			if err != nil {
+				return fmt.Errorf("migration: failed to roll back version %d, %v", migration.Version, err)
+			}
+			log.Infof("migration: rolled back version %d %s", migration.Version, migration.Name)
+			return nil
+		}
+		return nil
+	})
+}
+
+// Status return state of every known migration
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	statuses := make([]Status, 0, len(m.migrations))
+	err := m.locked(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, migration := range m.migrations {
+			appliedAt, ok := applied[migration.Version]
+			statuses = append(statuses, Status{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				Applied:   ok,
+				AppliedAt: appliedAt,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// run execute script and bookkeeping in one transaction
+func run(ctx context.Context, conn *pgxpool.Conn, script string, track func(tx pgx.Tx) error) error {
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+	_, err = tx.Exec(ctx, script)
+	if err != nil {
+		return err
+	}
+	err = track(tx)
+	if err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
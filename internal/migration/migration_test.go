@@ -0,0 +1,20 @@
+package migration
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := load()
+	require.NoError(t, err, "failed to load embedded migrations")
+	require.NotEmpty(t, migrations, "there are no migrations")
+	for i, m := range migrations {
+		require.NotEmpty(t, m.Up, "up script is empty")
+		require.NotEmpty(t, m.Down, "down script is empty")
+		if i > 0 {
+			require.Less(t, migrations[i-1].Version, m.Version, "migrations are not ordered by version")
+		}
+	}
+}
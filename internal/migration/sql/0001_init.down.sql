@@ -0,0 +1,2 @@
+drop table if exists adverts;
+drop table if exists persons;
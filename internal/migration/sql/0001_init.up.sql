@@ -0,0 +1,17 @@
+create table if not exists persons
+(
+    id           uuid primary key,
+    name         text not null,
+    password     text not null,
+    refreshToken text not null default ''
+);
+
+create table if not exists adverts
+(
+    id       uuid primary key,
+    address  text not null,
+    price    real not null check (price > 0),
+    owner_id uuid not null references persons (id) on delete cascade
+);
+
+create index if not exists adverts_owner_id_idx on adverts (owner_id);
@@ -0,0 +1 @@
+drop index if exists persons_name_uidx;
@@ -0,0 +1 @@
+create unique index if not exists persons_name_uidx on persons (name);
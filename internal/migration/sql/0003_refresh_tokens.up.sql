@@ -0,0 +1,16 @@
+create table if not exists refresh_tokens
+(
+    id         uuid primary key,
+    family_id  uuid        not null,
+    user_id    uuid        not null references persons (id) on delete cascade,
+    token_hash text        not null unique,
+    user_agent text        not null default '',
+    ip         text        not null default '',
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    used_at    timestamptz,
+    revoked_at timestamptz
+);
+
+create index if not exists refresh_tokens_family_id_idx on refresh_tokens (family_id);
+create index if not exists refresh_tokens_user_id_idx on refresh_tokens (user_id);
@@ -0,0 +1,2 @@
+alter table persons
+    drop column if exists roles;
@@ -0,0 +1,2 @@
+alter table persons
+    add column if not exists roles text[] not null default '{user}';
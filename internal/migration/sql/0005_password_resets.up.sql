@@ -0,0 +1,11 @@
+create table if not exists password_resets
+(
+    id         uuid primary key,
+    user_id    uuid        not null references persons (id) on delete cascade,
+    token_hash text        not null unique,
+    created_at timestamptz not null default now(),
+    expires_at timestamptz not null,
+    used_at    timestamptz
+);
+
+create index if not exists password_resets_user_id_idx on password_resets (user_id);
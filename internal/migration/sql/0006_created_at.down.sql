@@ -0,0 +1,9 @@
+drop index if exists adverts_price_id_idx;
+drop index if exists adverts_created_at_id_idx;
+drop index if exists persons_name_id_idx;
+drop index if exists persons_created_at_id_idx;
+
+alter table adverts
+    drop column if exists created_at;
+alter table persons
+    drop column if exists created_at;
@@ -0,0 +1,9 @@
+alter table persons
+    add column if not exists created_at timestamptz not null default now();
+alter table adverts
+    add column if not exists created_at timestamptz not null default now();
+
+create index if not exists persons_created_at_id_idx on persons (created_at, id);
+create index if not exists persons_name_id_idx on persons (name, id);
+create index if not exists adverts_created_at_id_idx on adverts (created_at, id);
+create index if not exists adverts_price_id_idx on adverts (price, id);
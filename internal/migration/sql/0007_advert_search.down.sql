@@ -0,0 +1,4 @@
+drop index if exists adverts_search_idx;
+
+alter table adverts
+    drop column if exists search;
@@ -0,0 +1,5 @@
+alter table adverts
+    add column if not exists search tsvector
+        generated always as (to_tsvector('simple', address)) stored;
+
+create index if not exists adverts_search_idx on adverts using gin (search);
@@ -0,0 +1,19 @@
+drop index if exists adverts_status_created_at_id_idx;
+drop index if exists adverts_search_idx;
+alter table adverts
+    drop column if exists search;
+
+alter table adverts
+    alter column price type real using price::real,
+    drop column if exists updated_at,
+    drop column if exists status,
+    drop column if exists currency,
+    drop column if exists category,
+    drop column if exists description,
+    drop column if exists title;
+
+alter table adverts
+    add column if not exists search tsvector
+        generated always as (to_tsvector('simple', address)) stored;
+
+create index if not exists adverts_search_idx on adverts using gin (search);
@@ -0,0 +1,31 @@
+drop index if exists adverts_search_idx;
+alter table adverts
+    drop column if exists search;
+
+alter table adverts
+    add column if not exists title       text        not null default '',
+    add column if not exists description text        not null default '',
+    add column if not exists category    text        not null default '',
+    add column if not exists currency    char(3)     not null default 'USD',
+    add column if not exists status      text        not null default 'published'
+        check (status in ('draft', 'published', 'archived')),
+    add column if not exists updated_at  timestamptz not null default now();
+
+-- adverts created before were public, so they stay published
+update adverts
+set title      = address,
+    updated_at = created_at;
+
+alter table adverts
+    alter column status set default 'draft',
+    alter column price type numeric(12, 2) using round(price::numeric, 2);
+
+alter table adverts
+    add column search tsvector generated always as (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', category), 'B') ||
+        setweight(to_tsvector('simple', address), 'B') ||
+        setweight(to_tsvector('simple', description), 'C')) stored;
+
+create index if not exists adverts_search_idx on adverts using gin (search);
+create index if not exists adverts_status_created_at_id_idx on adverts (status, created_at, id);
@@ -0,0 +1,6 @@
+drop index if exists adverts_lat_lon_idx;
+
+alter table adverts
+    drop constraint if exists adverts_location_check,
+    drop column if exists lon,
+    drop column if exists lat;
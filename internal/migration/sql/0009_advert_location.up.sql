@@ -0,0 +1,6 @@
+alter table adverts
+    add column if not exists lat double precision check (lat between -90 and 90),
+    add column if not exists lon double precision check (lon between -180 and 180),
+    add constraint adverts_location_check check ((lat is null) = (lon is null));
+
+create index if not exists adverts_lat_lon_idx on adverts (lat, lon) where lat is not null;
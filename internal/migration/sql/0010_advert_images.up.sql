@@ -0,0 +1,12 @@
+create table if not exists advert_images
+(
+    id           uuid primary key,
+    advert_id    uuid        not null references adverts (id) on delete cascade,
+    content_type text        not null,
+    size         bigint      not null check (size > 0),
+    width        int         not null check (width > 0),
+    height       int         not null check (height > 0),
+    created_at   timestamptz not null default now()
+);
+
+create index if not exists advert_images_advert_id_idx on advert_images (advert_id, created_at);
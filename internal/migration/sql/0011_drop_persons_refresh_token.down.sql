@@ -0,0 +1,2 @@
+alter table persons
+    add column if not exists refreshToken text not null default '';
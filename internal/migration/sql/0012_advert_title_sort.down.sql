@@ -0,0 +1 @@
+drop index if exists adverts_title_id_idx;
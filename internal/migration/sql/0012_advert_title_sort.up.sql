@@ -0,0 +1 @@
+create index if not exists adverts_title_id_idx on adverts (title, id);
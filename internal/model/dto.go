@@ -0,0 +1,210 @@
+// Package model File with api request and response structs and their mapping to stored structs
+package model
+
+import "time"
+
+// RegistrationRequest body of registration
+type RegistrationRequest struct {
+	Name     string `json:"name" validate:"required"`
+	Password string `json:"password" validate:"required"`
+}
+
+// ToPerson map registration to new user
+func (r RegistrationRequest) ToPerson() Person {
+	return Person{Name: r.Name, Password: r.Password}
+}
+
+// UserUpdateRequest body of user update
+type UserUpdateRequest struct {
+	Name string `json:"name" validate:"required"`
+}
+
+// ToPerson map update to user fields
+func (r UserUpdateRequest) ToPerson() Person {
+	return Person{Name: r.Name}
+}
+
+// UserResponse public fields of user
+type UserResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// NewUserResponse map user to its public fields
+func NewUserResponse(p Person) UserResponse {
+	return UserResponse{ID: p.ID, Name: p.Name, Roles: p.Roles, CreatedAt: p.CreatedAt}
+}
+
+// NewUserResponses map users to their public fields
+func NewUserResponses(persons []*Person) []UserResponse {
+	users := make([]UserResponse, 0, len(persons))
+	for _, p := range persons {
+		users = append(users, NewUserResponse(*p))
+	}
+	return users
+}
+
+// AdvertRequest body of advert creation and update, owner is taken from access token
+type AdvertRequest struct {
+	Title       string    `json:"title" validate:"required,max=200"`
+	Description string    `json:"description" validate:"max=5000"`
+	Category    string    `json:"category" validate:"required,max=50"`
+	Address     string    `json:"address" validate:"required"`
+	Price       Price     `json:"price" swaggertype:"number" example:"1250.50" validate:"gt=0"`
+	Currency    string    `json:"currency" example:"USD" validate:"required,iso4217"`
+	Location    *Location `json:"location,omitempty"`
+}
+
+// ToAdvert map request to advert of owner
+func (r AdvertRequest) ToAdvert(ownerID string) Advert {
+	return Advert{
+		Title:       r.Title,
+		Description: r.Description,
+		Category:    r.Category,
+		Address:     r.Address,
+		Price:       r.Price,
+		Currency:    r.Currency,
+		OwnerID:     ownerID,
+		Location:    r.Location,
+	}
+}
+
+// AdvertResponse public fields of advert
+type AdvertResponse struct {
+	ID          string    `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Category    string    `json:"category"`
+	Address     string    `json:"address"`
+	Price       Price     `json:"price" swaggertype:"number" example:"1250.50"`
+	Currency    string    `json:"currency" example:"USD"`
+	Status      string    `json:"status" enums:"draft,published,archived"`
+	OwnerID     string    `json:"ownerId"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Location    *Location `json:"location,omitempty"`
+}
+
+// NewAdvertResponse map advert to its public fields
+func NewAdvertResponse(a Advert) AdvertResponse {
+	return AdvertResponse{
+		ID:          a.ID,
+		Title:       a.Title,
+		Description: a.Description,
+		Category:    a.Category,
+		Address:     a.Address,
+		Price:       a.Price,
+		Currency:    a.Currency,
+		Status:      a.Status,
+		OwnerID:     a.OwnerID,
+		CreatedAt:   a.CreatedAt,
+		UpdatedAt:   a.UpdatedAt,
+		Location:    a.Location,
+	}
+}
+
+// NewAdvertResponses map adverts to their public fields
+func NewAdvertResponses(adverts []*Advert) []AdvertResponse {
+	result := make([]AdvertResponse, 0, len(adverts))
+	for _, a := range adverts {
+		result = append(result, NewAdvertResponse(*a))
+	}
+	return result
+}
+
+// PageMeta position of page in list
+type PageMeta struct {
+	NextCursor string `json:"nextCursor,omitempty"`
+	Total      int64  `json:"total"`
+}
+
+// UsersPageResponse page of users with public fields
+type UsersPageResponse struct {
+	Items []UserResponse `json:"items"`
+	PageMeta
+}
+
+// NewUsersPageResponse map page of users to public fields
+func NewUsersPageResponse(page UserPage) UsersPageResponse {
+	return UsersPageResponse{Items: NewUserResponses(page.Persons), PageMeta: PageMeta{NextCursor: page.NextCursor, Total: page.Total}}
+}
+
+// AdvertsPageResponse page of adverts with public fields
+type AdvertsPageResponse struct {
+	Items []AdvertResponse `json:"items"`
+	PageMeta
+}
+
+// NewAdvertsPageResponse map page of adverts to public fields
+func NewAdvertsPageResponse(page AdvertPage) AdvertsPageResponse {
+	return AdvertsPageResponse{Items: NewAdvertResponses(page.Adverts), PageMeta: PageMeta{NextCursor: page.NextCursor, Total: page.Total}}
+}
+
+// AdvertHitResponse advert found by search with its rank and highlighted matched fields
+type AdvertHitResponse struct {
+	AdvertResponse
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+// NewAdvertHitResponses map found adverts to public fields
+func NewAdvertHitResponses(hits []AdvertHit) []AdvertHitResponse {
+	result := make([]AdvertHitResponse, 0, len(hits))
+	for _, h := range hits {
+		result = append(result, AdvertHitResponse{AdvertResponse: NewAdvertResponse(h.Advert), Rank: h.Rank, Highlights: h.Highlights})
+	}
+	return result
+}
+
+// AdvertDistanceResponse advert found near point with distance to it in meters
+type AdvertDistanceResponse struct {
+	AdvertResponse
+	Distance float64 `json:"distance"`
+}
+
+// NewAdvertDistanceResponses map adverts found near point to public fields
+func NewAdvertDistanceResponses(found []AdvertDistance) []AdvertDistanceResponse {
+	result := make([]AdvertDistanceResponse, 0, len(found))
+	for _, f := range found {
+		result = append(result, AdvertDistanceResponse{AdvertResponse: NewAdvertResponse(f.Advert), Distance: f.Distance})
+	}
+	return result
+}
+
+// ImageResponse image of advert with links to its file and thumbnail
+type ImageResponse struct {
+	ID           string    `json:"id"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width"`
+	Height       int       `json:"height"`
+	CreatedAt    time.Time `json:"createdAt"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnailUrl"`
+}
+
+// NewImageResponse map image to public fields with links
+func NewImageResponse(i Image) ImageResponse {
+	url := "/adverts/" + i.AdvertID + "/images/" + i.ID
+	return ImageResponse{
+		ID:           i.ID,
+		ContentType:  i.ContentType,
+		Size:         i.Size,
+		Width:        i.Width,
+		Height:       i.Height,
+		CreatedAt:    i.CreatedAt,
+		URL:          url,
+		ThumbnailURL: url + "/thumbnail",
+	}
+}
+
+// NewImageResponses map images to public fields with links
+func NewImageResponses(images []Image) []ImageResponse {
+	result := make([]ImageResponse, 0, len(images))
+	for _, i := range images {
+		result = append(result, NewImageResponse(i))
+	}
+	return result
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserResponseHidesSecrets(t *testing.T) {
+	person := Person{
+		ID:       "a20fc586-d9d2-4969-909f-d00bf42aa88a",
+		Name:     "Egor Tihonov",
+		Password: "$2a$10$hash",
+		Roles:    []string{RoleUser},
+	}
+	for _, value := range []interface{}{person, NewUserResponse(person), NewUserResponses([]*Person{&person})} {
+		body, err := json.Marshal(value)
+		require.NoError(t, err)
+		require.NotContains(t, string(body), person.Password)
+		require.Contains(t, string(body), `"name":"Egor Tihonov"`)
+	}
+}
+
+func TestAdvertRequest_ToAdvert(t *testing.T) {
+	request := AdvertRequest{Title: "Flat", Category: "flats", Address: "Minsk", Price: 10000, Currency: "BYN"}
+	advert := request.ToAdvert("a20fc586-d9d2-4969-909f-d00bf42aa88a")
+	require.Equal(t, Advert{Title: "Flat", Category: "flats", Address: "Minsk", Price: 10000, Currency: "BYN",
+		OwnerID: "a20fc586-d9d2-4969-909f-d00bf42aa88a"}, advert)
+	advert.Status = AdvertDraft
+	require.Equal(t, AdvertResponse{Title: "Flat", Category: "flats", Address: "Minsk", Price: 10000, Currency: "BYN",
+		Status: AdvertDraft, OwnerID: advert.OwnerID}, NewAdvertResponse(advert))
+}
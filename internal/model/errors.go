@@ -0,0 +1,76 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Domain errors, repositories and service wrap them with %w so handlers can pick http status
+var (
+	// ErrNotFound requested entity doesnt exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict entity with the same unique fields already exists
+	ErrConflict = errors.New("already exists")
+	// ErrValidation incoming data is invalid
+	ErrValidation = errors.New("validation failed")
+	// ErrUnauthorized credentials or tokens are invalid
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrForbidden user is authenticated but cant do this action
+	ErrForbidden = errors.New("forbidden")
+	// ErrTooManyRequests client makes attempts too often
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrLocked account is temporarily locked
+	ErrLocked = errors.New("locked")
+	// ErrTooLarge uploaded data is bigger than allowed
+	ErrTooLarge = errors.New("too large")
+	// ErrUnsupportedMedia uploaded data has type which is not accepted
+	ErrUnsupportedMedia = errors.New("unsupported media type")
+)
+
+// FieldError problem with one field of request
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError request breaks rules for its fields, it is ErrValidation with details for client
+type ValidationError struct {
+	Fields []FieldError
+}
+
+// Error join problems of all fields
+func (e *ValidationError) Error() string {
+	problems := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		problems = append(problems, f.Field+": "+f.Message)
+	}
+	return strings.Join(problems, "; ")
+}
+
+// Unwrap make ValidationError match ErrValidation
+func (e *ValidationError) Unwrap() error {
+	return ErrValidation
+}
+
+// RetryAfterError request can be repeated only after some time
+type RetryAfterError struct {
+	After time.Duration
+	Err   error
+}
+
+// Error describe wrapped error
+func (e *RetryAfterError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap return wrapped error
+func (e *RetryAfterError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorResponse body of failed request
+type ErrorResponse struct {
+	Message string       `json:"message"`
+	Details []FieldError `json:"details,omitempty"`
+}
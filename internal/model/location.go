@@ -0,0 +1,55 @@
+// Package model File with geographic location of advert
+package model
+
+import (
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// Location point on earth in degrees
+type Location struct {
+	Lat float64 `json:"lat" example:"53.9" validate:"gte=-90,lte=90"`
+	Lon float64 `json:"lon" example:"27.56" validate:"gte=-180,lte=180"`
+}
+
+// geoJSONPoint location as it is stored in mongo for 2dsphere index
+type geoJSONPoint struct {
+	Type        string    `bson:"type"`
+	Coordinates []float64 `bson:"coordinates"`
+}
+
+// MarshalBSON store location as geojson point, longitude goes first
+func (l Location) MarshalBSON() ([]byte, error) {
+	return bson.Marshal(geoJSONPoint{Type: "Point", Coordinates: []float64{l.Lon, l.Lat}})
+}
+
+// UnmarshalBSON read location from geojson point
+func (l *Location) UnmarshalBSON(data []byte) error {
+	point := geoJSONPoint{}
+	err := bson.Unmarshal(data, &point)
+	if err != nil {
+		return err
+	}
+	if point.Type != "Point" || len(point.Coordinates) != 2 {
+		return fmt.Errorf("location %s isnt geojson point", bson.Raw(data))
+	}
+	l.Lon, l.Lat = point.Coordinates[0], point.Coordinates[1]
+	return nil
+}
+
+// DefaultNearbyRadius radius of nearby search in meters when it is not set
+const DefaultNearbyRadius = 5000
+
+// NearbyParams circle to find adverts in, radius is in meters
+type NearbyParams struct {
+	Location
+	Radius float64 `json:"radius" validate:"gt=0,lte=200000"`
+	Limit  int     `json:"limit" validate:"min=1,max=100"`
+}
+
+// AdvertDistance advert found near point with distance to it in meters
+type AdvertDistance struct {
+	Advert   Advert
+	Distance float64
+}
@@ -0,0 +1,28 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestLocation_BSON(t *testing.T) {
+	raw, err := bson.Marshal(Advert{ID: "1", Location: &Location{Lat: 53.9, Lon: 27.56}})
+	require.NoError(t, err)
+	point := bson.Raw(raw).Lookup("location").Document()
+	require.Equal(t, "Point", point.Lookup("type").StringValue())
+	coordinates, err := point.Lookup("coordinates").Array().Values()
+	require.NoError(t, err)
+	require.Equal(t, 27.56, coordinates[0].Double(), "longitude goes first in geojson")
+	require.Equal(t, 53.9, coordinates[1].Double())
+
+	decoded := Advert{}
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+	require.Equal(t, &Location{Lat: 53.9, Lon: 27.56}, decoded.Location)
+
+	raw, err = bson.Marshal(Advert{ID: "2"})
+	require.NoError(t, err)
+	_, err = bson.Raw(raw).LookupErr("location")
+	require.Error(t, err, "advert without location must not have location field")
+}
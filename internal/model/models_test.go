@@ -0,0 +1,31 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdvert_VisibleTo(t *testing.T) {
+	const owner = "a20fc586-d9d2-4969-909f-d00bf42aa88a"
+	stranger := &Principal{ID: "1fc29d3c-d5b0-4b0a-9e79-1c9bb1bb2a3d", Roles: []string{RoleUser}}
+	admin := &Principal{ID: "25a64c4c-139f-4303-a83d-f31095a114af", Roles: []string{RoleUser, RoleAdmin}}
+	testData := []struct {
+		status  string
+		viewer  *Principal
+		visible bool
+	}{
+		{AdvertPublished, nil, true},
+		{AdvertPublished, stranger, true},
+		{AdvertDraft, nil, false},
+		{AdvertDraft, stranger, false},
+		{AdvertArchived, stranger, false},
+		{AdvertDraft, &Principal{ID: owner}, true},
+		{AdvertArchived, &Principal{ID: owner}, true},
+		{AdvertDraft, admin, true},
+	}
+	for _, data := range testData {
+		advert := Advert{OwnerID: owner, Status: data.status}
+		require.Equal(t, data.visible, advert.VisibleTo(data.viewer), "%s advert for %+v", data.status, data.viewer)
+	}
+}
@@ -0,0 +1,56 @@
+// Package model File with structs for paginated lists
+package model
+
+// fields which lists can be sorted by
+const (
+	SortName    = "name"
+	SortTitle   = "title"
+	SortPrice   = "price"
+	SortCreated = "created"
+)
+
+// page size limits
+const (
+	DefaultPageLimit = 20
+	MaxPageLimit     = 100
+)
+
+// ListParams which part of list is requested, Cursor has priority over Offset
+type ListParams struct {
+	Limit  int    `json:"limit" validate:"min=1,max=100"`
+	Offset int    `json:"offset" validate:"min=0"`
+	Cursor string `json:"cursor"`
+	Sort   string `json:"sort"`
+	Desc   bool   `json:"desc"`
+}
+
+// AdvertFilter conditions for adverts in list, nil price bound and empty strings are not checked
+type AdvertFilter struct {
+	MinPrice *Price `json:"minPrice" validate:"omitempty,gte=0"`
+	MaxPrice *Price `json:"maxPrice" validate:"omitempty,gte=0"`
+	Address  string `json:"address"`
+	Category string `json:"category"`
+	Currency string `json:"currency" validate:"omitempty,iso4217"`
+	Status   string `json:"status" validate:"omitempty,oneof=draft published archived"`
+}
+
+// UserPage one page of users, NextCursor is empty on the last page
+type UserPage struct {
+	Persons    []*Person
+	NextCursor string
+	Total      int64
+}
+
+// AdvertPage one page of adverts, NextCursor is empty on the last page
+type AdvertPage struct {
+	Adverts    []*Advert
+	NextCursor string
+	Total      int64
+}
+
+// AdvertHit advert found by search, Highlights are its matched fields with matched words in marks
+type AdvertHit struct {
+	Advert     Advert
+	Rank       float64
+	Highlights map[string]string
+}
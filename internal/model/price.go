@@ -0,0 +1,140 @@
+// Package model File with decimal price of advert
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Price amount of money in hundredths, so it is stored and compared without float rounding
+type Price int64
+
+// maxPriceDigits digits of integer part, as in numeric(12,2) column
+const maxPriceDigits = 10
+
+// ParsePrice parse decimal like "12", "12.5" or "12.50", more than two fraction digits are rejected
+func ParsePrice(s string) (Price, error) {
+	whole, fraction := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, fraction = s[:i], s[i+1:]
+	}
+	negative := strings.HasPrefix(whole, "-")
+	whole = strings.TrimPrefix(whole, "-")
+	if whole == "" || len(whole) > maxPriceDigits || len(fraction) > 2 || !digits(whole) || !digits(fraction) {
+		return 0, fmt.Errorf("%q is not price with at most 2 fraction digits", s)
+	}
+	fraction += strings.Repeat("0", 2-len(fraction))
+	n, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not price, %v", s, err)
+	}
+	if negative {
+		n = -n
+	}
+	return Price(n), nil
+}
+
+// digits check that s contains only ascii digits
+func digits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String format price with two fraction digits
+func (p Price) String() string {
+	sign := ""
+	n := int64(p)
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, n/100, n%100)
+}
+
+// MarshalJSON write price as json number with two fraction digits
+func (p Price) MarshalJSON() ([]byte, error) {
+	return []byte(p.String()), nil
+}
+
+// UnmarshalJSON read price from json number or string
+func (p *Price) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	price, err := ParsePrice(string(data))
+	if err != nil {
+		return err
+	}
+	*p = price
+	return nil
+}
+
+// Value store price in postgres numeric column
+func (p Price) Value() (driver.Value, error) {
+	return p.String(), nil
+}
+
+// Scan read price from postgres numeric column, pgx passes it as text like "1250e-2"
+func (p *Price) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case string:
+		return p.setDecimal(v)
+	case []byte:
+		return p.setDecimal(string(v))
+	case int64:
+		*p = Price(v * 100)
+	case float64:
+		*p = Price(math.Round(v * 100))
+	default:
+		return fmt.Errorf("cant scan %T into price", src)
+	}
+	return nil
+}
+
+// setDecimal set price from decimal in any notation, digits after hundredths are rounded
+func (p *Price) setDecimal(s string) error {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return fmt.Errorf("%q is not decimal", s)
+	}
+	hundredths, _ := r.Mul(r, big.NewRat(100, 1)).Float64()
+	*p = Price(math.Round(hundredths))
+	return nil
+}
+
+// MarshalBSONValue store price in mongo as decimal128
+func (p Price) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(p.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	return bson.MarshalValue(d)
+}
+
+// UnmarshalBSONValue read price from mongo decimal128 or number stored by earlier versions
+func (p *Price) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bsontype.Decimal128:
+		return p.setDecimal(value.Decimal128().String())
+	case bsontype.Double:
+		*p = Price(math.Round(value.Double() * 100))
+	case bsontype.Int32:
+		*p = Price(int64(value.Int32()) * 100)
+	case bsontype.Int64:
+		*p = Price(value.Int64() * 100)
+	default:
+		return fmt.Errorf("cant decode %s into price", t)
+	}
+	return nil
+}
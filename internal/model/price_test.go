@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestParsePrice(t *testing.T) {
+	for s, expected := range map[string]Price{"12": 1200, "12.5": 1250, "12.05": 1205, "0.99": 99, "-3.10": -310} {
+		price, err := ParsePrice(s)
+		require.NoError(t, err, s)
+		require.Equal(t, expected, price, s)
+	}
+	for _, s := range []string{"", ".5", "12.345", "1e3", "12,50", "abc", "12345678901"} {
+		_, err := ParsePrice(s)
+		require.Error(t, err, "%q must be rejected", s)
+	}
+	require.Equal(t, "12.05", Price(1205).String())
+	require.Equal(t, "-0.50", Price(-50).String())
+}
+
+func TestPrice_JSON(t *testing.T) {
+	body, err := json.Marshal(struct{ Price Price }{1250})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"Price":12.50}`, string(body))
+	var decoded struct{ Price Price }
+	require.NoError(t, json.Unmarshal([]byte(`{"Price":"7.1"}`), &decoded))
+	require.Equal(t, Price(710), decoded.Price)
+	require.Error(t, json.Unmarshal([]byte(`{"Price":0.001}`), &decoded))
+}
+
+func TestPrice_Scan(t *testing.T) {
+	var p Price
+	for src, expected := range map[interface{}]Price{"1250e-2": 1250, "12.50": 1250, "3e0": 300, int64(4): 400, 0.1: 10} {
+		require.NoError(t, p.Scan(src))
+		require.Equal(t, expected, p, "%v", src)
+	}
+	require.Error(t, p.Scan(true))
+}
+
+func TestPrice_BSON(t *testing.T) {
+	raw, err := bson.Marshal(bson.M{"price": Price(1250)})
+	require.NoError(t, err)
+	require.Equal(t, "12.50", bson.Raw(raw).Lookup("price").Decimal128().String())
+	var decoded struct{ Price Price }
+	require.NoError(t, bson.Unmarshal(raw, &decoded))
+	require.Equal(t, Price(1250), decoded.Price)
+
+	legacy, err := bson.Marshal(bson.M{"price": float64(float32(99.99))}) // stored as float32 before
+	require.NoError(t, err)
+	require.NoError(t, bson.Unmarshal(legacy, &decoded))
+	require.Equal(t, Price(9999), decoded.Price)
+}
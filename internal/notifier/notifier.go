@@ -0,0 +1,90 @@
+// Package notifier : file contains delivery of messages to users
+package notifier
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/labstack/gommon/log"
+)
+
+// Notifier send messages to users, real delivery (mail, sms) implements it
+type Notifier interface {
+	PasswordReset(ctx context.Context, person model.Person, token string, expiresAt time.Time) error
+}
+
+// New create notifier by its kind from config
+func New(cfg model.NotifierConfig) (Notifier, error) {
+	switch cfg.Kind {
+	case "none":
+		return NoopNotifier{}, nil
+	case "log":
+		return LogNotifier{}, nil
+	case "file":
+		return NewFileNotifier(cfg.File), nil
+	}
+	return nil, fmt.Errorf("notifier: unknown kind %q, expected none, log or file", cfg.Kind)
+}
+
+// NoopNotifier drop messages, used until real delivery is configured, so tokens dont leak to log
+type NoopNotifier struct{}
+
+// PasswordReset log that password reset of user isnt delivered, token itself isnt logged
+func (NoopNotifier) PasswordReset(_ context.Context, person model.Person, _ string, _ time.Time) error {
+	log.Warnf("notifier: password reset token for user %s isnt delivered, notifier isnt configured", person.Name)
+	return nil
+}
+
+// LogNotifier write messages to server log, for development only, enabled explicitly by NOTIFIER=log
+type LogNotifier struct{}
+
+// PasswordReset log password reset token of user
+func (LogNotifier) PasswordReset(_ context.Context, person model.Person, token string, expiresAt time.Time) error {
+	log.Infof("notifier: password reset token for user %s: %s, expires at %s", person.Name, token, expiresAt.Format(time.RFC3339))
+	return nil
+}
+
+// message line written by FileNotifier
+type message struct {
+	Kind      string    `json:"kind"`
+	UserID    string    `json:"userId"`
+	Name      string    `json:"name"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// FileNotifier append messages as json lines to file, can be read by tests and local tools
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+// NewFileNotifier create notifier writing to file by path
+func NewFileNotifier(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+// PasswordReset append password reset token of user to file
+func (f *FileNotifier) PasswordReset(_ context.Context, person model.Person, token string, expiresAt time.Time) error {
+	line, err := json.Marshal(message{Kind: "password-reset", UserID: person.ID, Name: person.Name, Token: token, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("notifier: can't open %s, %w", f.path, err)
+	}
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("notifier: can't write %s, %w", f.path, err)
+	}
+	return file.Close()
+}
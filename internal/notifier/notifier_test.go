@@ -0,0 +1,44 @@
+package notifier
+
+import (
+	"awesomeProject/internal/model"
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileNotifier(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "notifications.log")
+	n, err := New(model.NotifierConfig{Kind: "file", File: path})
+	require.NoError(t, err)
+	person := model.Person{ID: "a20fc586-d9d2-4969-909f-d00bf42aa88a", Name: "Egor Tihonov"}
+	require.NoError(t, n.PasswordReset(context.Background(), person, "first", time.Now()))
+	require.NoError(t, n.PasswordReset(context.Background(), person, "second", time.Now()))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	var tokens []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		m := message{}
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &m))
+		require.Equal(t, person.ID, m.UserID)
+		tokens = append(tokens, m.Token)
+	}
+	require.Equal(t, []string{"first", "second"}, tokens)
+
+	n, err = New(model.NotifierConfig{Kind: "none"})
+	require.NoError(t, err)
+	require.Equal(t, NoopNotifier{}, n)
+	require.NoError(t, n.PasswordReset(context.Background(), person, "third", time.Now()))
+
+	_, err = New(model.NotifierConfig{Kind: "pigeon"})
+	require.Error(t, err, "unknown notifier created")
+}
@@ -0,0 +1,80 @@
+// Package password : file contains rules which passwords must satisfy
+package password
+
+import (
+	"awesomeProject/internal/model"
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+)
+
+// Policy rules for new passwords
+type Policy struct {
+	minLength  int
+	minClasses int
+	denied     map[string]struct{}
+}
+
+// NewPolicy create policy from config, denylist file contains one common password per line, # starts comment
+func NewPolicy(cfg model.PasswordConfig) (*Policy, error) {
+	p := &Policy{minLength: cfg.MinLength, minClasses: cfg.MinClasses, denied: map[string]struct{}{}}
+	if cfg.DenylistFile == "" {
+		return p, nil
+	}
+	file, err := os.Open(cfg.DenylistFile)
+	if err != nil {
+		return nil, fmt.Errorf("password: can't open denylist, %w", err)
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p.denied[strings.ToLower(line)] = struct{}{}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("password: can't read denylist, %w", err)
+	}
+	return p, nil
+}
+
+// Check return ValidationError with all broken rules, field is name of password field in request
+func (p *Policy) Check(field, password string) error {
+	var problems []model.FieldError
+	if len([]rune(password)) < p.minLength {
+		problems = append(problems, model.FieldError{Field: field, Message: fmt.Sprintf("must be at least %d characters long", p.minLength)})
+	}
+	if classes(password) < p.minClasses {
+		problems = append(problems, model.FieldError{Field: field,
+			Message: fmt.Sprintf("must contain at least %d of: lowercase letters, uppercase letters, digits, symbols", p.minClasses)})
+	}
+	if _, ok := p.denied[strings.ToLower(password)]; ok {
+		problems = append(problems, model.FieldError{Field: field, Message: "is too common"})
+	}
+	if len(problems) > 0 {
+		return &model.ValidationError{Fields: problems}
+	}
+	return nil
+}
+
+// classes count kinds of characters used in password
+func classes(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		case !unicode.IsSpace(r):
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
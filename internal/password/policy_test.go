@@ -0,0 +1,42 @@
+package password
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	denylist := filepath.Join(t.TempDir(), "common.txt")
+	require.NoError(t, os.WriteFile(denylist, []byte("# common passwords\nPassword123\n\nqwerty\n"), 0o600))
+	policy, err := NewPolicy(model.PasswordConfig{MinLength: 8, MinClasses: 3, DenylistFile: denylist})
+	require.NoError(t, err)
+	testData := []struct {
+		password string
+		problems int
+	}{
+		{"tujh2004Egor", 0},
+		{"Tj2!", 1},
+		{"onlylowercase", 1},
+		{"password123", 2},
+		{"qwerty", 3},
+		{"", 2},
+	}
+	for _, data := range testData {
+		err := policy.Check("password", data.password)
+		if data.problems == 0 {
+			require.NoError(t, err, "password %q is rejected", data.password)
+			continue
+		}
+		var validationErr *model.ValidationError
+		require.True(t, errors.As(err, &validationErr), "password %q is accepted", data.password)
+		require.True(t, errors.Is(err, model.ErrValidation))
+		require.Len(t, validationErr.Fields, data.problems, "wrong problems of %q: %v", data.password, err)
+	}
+	_, err = NewPolicy(model.PasswordConfig{DenylistFile: filepath.Join(t.TempDir(), "missing.txt")})
+	require.Error(t, err, "policy created without denylist")
+}
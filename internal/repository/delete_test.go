@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/context"
+)
+
+func TestRepository_DeleteCascade(t *testing.T) {
+	testData := []struct {
+		name string
+		rps  Repository
+	}{
+		{"postgres", &PRepository{PPool: Pool}},
+		{"mongo", &MRepository{MPool: PoolM}},
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	for _, data := range testData {
+		now := time.Now()
+		userID := createTestPerson(ctx, t, data.rps)
+		advertID, err := data.rps.CreateAdvert(ctx, &model.Advert{Title: "Flat", Category: "flats", Address: "Minsk", Price: 10000,
+			Currency: "BYN", Status: model.AdvertPublished, OwnerID: userID})
+		require.NoError(t, err, "%s: cannot create advert", data.name)
+		err = data.rps.CreateImage(ctx, &model.Image{ID: uuid.New().String(), AdvertID: advertID, ContentType: "image/png", Size: 1,
+			Width: 1, Height: 1}, 10)
+		require.NoError(t, err, "%s: cannot create image", data.name)
+		tokenHash := uuid.New().String()
+		err = data.rps.CreateRefreshToken(ctx, &model.RefreshToken{ID: uuid.New().String(), FamilyID: uuid.New().String(),
+			UserID: userID, TokenHash: tokenHash, CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+		require.NoError(t, err, "%s: cannot create refresh token", data.name)
+		resetHash := uuid.New().String()
+		err = data.rps.CreatePasswordReset(ctx, &model.PasswordReset{ID: uuid.New().String(), UserID: userID, TokenHash: resetHash,
+			CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+		require.NoError(t, err, "%s: cannot create password reset", data.name)
+
+		require.NoError(t, data.rps.Delete(ctx, userID), "%s: cannot delete user", data.name)
+		_, err = data.rps.SelectAdvertByID(ctx, advertID)
+		require.True(t, errors.Is(err, model.ErrNotFound), "%s: advert of deleted user is left: %v", data.name, err)
+		adverts, err := data.rps.SelectAdvertsByOwner(ctx, userID, "")
+		require.NoError(t, err)
+		require.Empty(t, adverts, "%s: adverts of deleted user are listed", data.name)
+		images, err := data.rps.SelectImages(ctx, advertID)
+		require.NoError(t, err)
+		require.Empty(t, images, "%s: images of deleted user are left", data.name)
+		_, err = data.rps.SelectRefreshToken(ctx, tokenHash)
+		require.True(t, errors.Is(err, model.ErrNotFound), "%s: refresh token of deleted user is left: %v", data.name, err)
+		_, err = data.rps.UsePasswordReset(ctx, resetHash)
+		require.True(t, errors.Is(err, model.ErrNotFound), "%s: password reset of deleted user is left: %v", data.name, err)
+		err = data.rps.Delete(ctx, userID)
+		require.True(t, errors.Is(err, model.ErrNotFound), "%s: deleted user is deleted twice: %v", data.name, err)
+	}
+}
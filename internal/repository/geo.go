@@ -0,0 +1,31 @@
+// Package repository : file contains geographic calculations shared by all DBs
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"math"
+)
+
+// earthRadius mean radius of earth in meters
+const earthRadius = 6371000.0
+
+// geoBox bounding box of circle, longitude is not bounded when circle covers pole or crosses antimeridian
+type geoBox struct {
+	MinLat, MaxLat float64
+	MinLon, MaxLon float64
+	LonBounded     bool
+}
+
+// boundingBox find box containing all points not farther than radius meters from center
+func boundingBox(center model.Location, radius float64) geoBox {
+	angle := radius / earthRadius
+	dLat := angle * 180 / math.Pi
+	box := geoBox{MinLat: center.Lat - dLat, MaxLat: center.Lat + dLat}
+	if box.MinLat <= -90 || box.MaxLat >= 90 || angle >= math.Pi/2 {
+		return box
+	}
+	dLon := math.Asin(math.Sin(angle)/math.Cos(center.Lat*math.Pi/180)) * 180 / math.Pi
+	box.MinLon, box.MaxLon = center.Lon-dLon, center.Lon+dLon
+	box.LonBounded = box.MinLon >= -180 && box.MaxLon <= 180
+	return box
+}
@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBoundingBox(t *testing.T) {
+	minsk := model.Location{Lat: 53.9, Lon: 27.5667}
+	box := boundingBox(minsk, 10000)
+	require.True(t, box.LonBounded)
+	require.InDelta(t, 0.0899, box.MaxLat-minsk.Lat, 0.0001)
+	require.InDelta(t, minsk.Lat-box.MinLat, box.MaxLat-minsk.Lat, 1e-9)
+	require.Greater(t, box.MaxLon-minsk.Lon, box.MaxLat-minsk.Lat, "degree of longitude is shorter away from equator")
+
+	box = boundingBox(model.Location{Lat: 89.95, Lon: 0}, 10000)
+	require.False(t, box.LonBounded, "circle covers pole")
+
+	box = boundingBox(model.Location{Lat: 0, Lon: 179.99}, 10000)
+	require.False(t, box.LonBounded, "circle crosses antimeridian")
+}
@@ -0,0 +1,47 @@
+// Package repository : file contains advert lifecycle in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ensureAdvertFields fill fields of adverts created when advert had only address and float price,
+// such adverts were public so they become published
+func (m *MRepository) ensureAdvertFields(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("advert").UpdateMany(ctx,
+		bson.D{{Key: "status", Value: bson.D{{Key: "$exists", Value: false}}}},
+		mongo.Pipeline{{{Key: "$set", Value: bson.D{
+			{Key: "title", Value: "$address"},
+			{Key: "description", Value: ""},
+			{Key: "category", Value: ""},
+			{Key: "price", Value: bson.D{{Key: "$round", Value: bson.A{bson.D{{Key: "$toDecimal", Value: "$price"}}, 2}}}},
+			{Key: "currency", Value: "USD"},
+			{Key: "status", Value: model.AdvertPublished},
+			{Key: "updatedat", Value: "$createdat"},
+		}}}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	return nil
+}
+
+// UpdateAdvertStatus move advert from status to another one, advert which status was changed by someone else is conflict
+func (m *MRepository) UpdateAdvertStatus(ctx context.Context, id, from, to string, updatedAt time.Time) error {
+	collection := m.MPool.Database("person").Collection("advert")
+	res, err := collection.UpdateOne(ctx,
+		bson.D{{Key: "id", Value: id}, {Key: "status", Value: from}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: to}, {Key: "updatedat", Value: updatedAt}}}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("advert isnt %s anymore: %w", from, model.ErrConflict)
+	}
+	return nil
+}
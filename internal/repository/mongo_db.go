@@ -4,19 +4,35 @@ package repository
 import (
 	"awesomeProject/internal/model"
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// mongoIllegalOperation code of error about operation which server doesnt support
+const mongoIllegalOperation = 20
+
 // MRepository create connection with MongoDB
 type MRepository struct {
 	MPool *mongo.Client
 }
 
+// mongoError wrap mongo error about entity into domain error
+func mongoError(err error, entity string) error {
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("%s with this id doesnt exist: %w", entity, model.ErrNotFound)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return fmt.Errorf("%s already exists: %w", entity, model.ErrConflict)
+	}
+	return fmt.Errorf("mongo: unable to process %s, %w", entity, err)
+}
+
 // Create add new user to db
 func (m *MRepository) Create(ctx context.Context, person *model.Person) (string, error) {
 
@@ -26,10 +42,11 @@ func (m *MRepository) Create(ctx context.Context, person *model.Person) (string,
 		{Key: "id", Value: newID},
 		{Key: "name", Value: person.Name},
 		{Key: "password", Value: person.Password},
-		{Key: "refreshtoken", Value: person.RefreshToken},
+		{Key: "roles", Value: person.Roles},
+		{Key: "createdat", Value: creationTime(person.CreatedAt)},
 	})
 	if err != nil {
-		return "", fmt.Errorf("mongo: unable to create new user: %v", err)
+		return "", mongoError(err, "user")
 	}
 	return newID, nil
 }
@@ -38,80 +55,289 @@ func (m *MRepository) Create(ctx context.Context, person *model.Person) (string,
 func (m *MRepository) Update(ctx context.Context, id string, person *model.Person) error {
 
 	collection := m.MPool.Database("person").Collection("person")
-	_, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+	res, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
 		{Key: "name", Value: person.Name},
-		{Key: "password", Value: person.Password},
 	}}})
 	if err != nil {
-		return fmt.Errorf("mongo: unable to update user %v", err)
+		return mongoError(err, "user")
+	}
+	if res.MatchedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "user")
 	}
 	return nil
 }
 
-// UpdateAuth add user refresh token
-func (m *MRepository) UpdateAuth(ctx context.Context, id, refreshToken string) error {
+// UpdateRoles replace roles of user
+func (m *MRepository) UpdateRoles(ctx context.Context, id string, roles []string) error {
 	collection := m.MPool.Database("person").Collection("person")
-	_, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
-		{Key: "refreshtoken", Value: refreshToken},
+	res, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "roles", Value: roles},
 	}}})
 	if err != nil {
-		return fmt.Errorf("mongo: unable to update user %v", err)
+		return mongoError(err, "user")
+	}
+	if res.MatchedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "user")
 	}
 	return nil
 }
 
-// SelectAll take all users from db
-func (m *MRepository) SelectAll(ctx context.Context) ([]*model.Person, error) {
-	var users []*model.Person
+// UpdatePassword replace password hash of user
+func (m *MRepository) UpdatePassword(ctx context.Context, id, password string) error {
 	collection := m.MPool.Database("person").Collection("person")
-	c, err := collection.Find(ctx, bson.M{})
+	res, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, bson.D{{Key: "$set", Value: bson.D{
+		{Key: "password", Value: password},
+	}}})
 	if err != nil {
-		return nil, fmt.Errorf("mongo: unable to select all users %v", err)
+		return mongoError(err, "user")
 	}
-	for c.Next(ctx) {
-		user := model.Person{}
-		err := c.Decode(&user)
-		if err != nil {
-			return users, err
-		}
-		users = append(users, &user)
+	if res.MatchedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "user")
 	}
-	return users, nil
+	return nil
 }
 
-// Delete user from db
+// Delete user from db with his adverts, their images, refresh tokens and password resets, like postgres does by
+// foreign keys. It runs in transaction, standalone server without transactions deletes them one by one
 func (m *MRepository) Delete(ctx context.Context, id string) error {
-	collection := m.MPool.Database("person").Collection("person")
-	_, err := collection.DeleteOne(ctx, bson.D{primitive.E{Key: "id", Value: id}})
+	session, err := m.MPool.StartSession()
+	if err != nil {
+		return mongoError(err, "user")
+	}
+	defer session.EndSession(ctx)
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, m.deletePerson(sc, id)
+	})
+	if noTransactions(err) {
+		return m.deletePerson(ctx, id)
+	}
+	return err
+}
+
+// deletePerson delete user after everything what belongs to him, so failed delete can be repeated
+func (m *MRepository) deletePerson(ctx context.Context, id string) error {
+	db := m.MPool.Database("person")
+	advertIDs, err := db.Collection("advert").Distinct(ctx, "id", bson.D{{Key: "ownerid", Value: id}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	if len(advertIDs) > 0 {
+		_, err = db.Collection("advertimage").DeleteMany(ctx, bson.D{{Key: "advertid", Value: bson.D{{Key: "$in", Value: advertIDs}}}})
+		if err != nil {
+			return mongoError(err, "image")
+		}
+	}
+	_, err = db.Collection("advert").DeleteMany(ctx, bson.D{{Key: "ownerid", Value: id}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	_, err = db.Collection("refreshtoken").DeleteMany(ctx, bson.D{{Key: "userid", Value: id}})
+	if err != nil {
+		return mongoError(err, "refresh token")
+	}
+	_, err = db.Collection("passwordreset").DeleteMany(ctx, bson.D{{Key: "userid", Value: id}})
+	if err != nil {
+		return mongoError(err, "password reset")
+	}
+	res, err := db.Collection("person").DeleteOne(ctx, bson.D{primitive.E{Key: "id", Value: id}})
 	if err != nil {
-		return fmt.Errorf("mongo: unable to delete user, %v", err)
+		return mongoError(err, "user")
+	}
+	if res.DeletedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "user")
 	}
 	return nil
 }
 
+// noTransactions check if error is about transactions which standalone server doesnt support
+func noTransactions(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == mongoIllegalOperation
+}
+
 // SelectByID select exist user from db by his id
 func (m *MRepository) SelectByID(ctx context.Context, id string) (model.Person, error) {
 	user := model.Person{}
 	collection := m.MPool.Database("person").Collection("person")
 	err := collection.FindOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}).Decode(&user)
 	if err != nil {
-		return user, err
+		return model.Person{}, mongoError(err, "user")
 	}
 	return user, nil
 }
 
-// SelectByIDAuth take from user his refresh token
-func (m *MRepository) SelectByIDAuth(ctx context.Context, id string) (model.Person, error) {
+// SelectByName select exist user from db by his unique name
+func (m *MRepository) SelectByName(ctx context.Context, name string) (model.Person, error) {
 	user := model.Person{}
 	collection := m.MPool.Database("person").Collection("person")
-	err := collection.FindOne(ctx, bson.D{primitive.E{Key: "id", Value: id},
-		{Key: "name", Value: 0},
-		{Key: "works", Value: 0},
-		{Key: "age", Value: 0},
-		{Key: "password", Value: 0},
-	}).Decode(&user)
+	err := collection.FindOne(ctx, bson.D{primitive.E{Key: "name", Value: name}}).Decode(&user)
 	if err != nil {
-		return user, err
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return model.Person{}, fmt.Errorf("user with this name doesnt exist: %w", model.ErrNotFound)
+		}
+		return model.Person{}, mongoError(err, "user")
 	}
 	return user, nil
 }
+
+// EnsureIndexes create unique indexes on id for users and adverts, unique name index for users, owner index for adverts
+// indexes for lists, advert search, location and images, refresh tokens and password resets, fields of adverts created before they were stored
+func (m *MRepository) EnsureIndexes(ctx context.Context) error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}
+	db := m.MPool.Database("person")
+	for _, name := range []string{"person", "advert"} {
+		_, err := db.Collection(name).Indexes().CreateOne(ctx, index)
+		if err != nil {
+			return fmt.Errorf("mongo: unable to create index on %s collection, %v", name, err)
+		}
+	}
+	_, err := db.Collection("person").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("mongo: unable to create name index on person collection, %v", err)
+	}
+	_, err = db.Collection("advert").Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "ownerid", Value: 1}}})
+	if err != nil {
+		return fmt.Errorf("mongo: unable to create owner index on advert collection, %v", err)
+	}
+	err = m.ensureListIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.ensureAdvertFields(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.ensureSearchIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.ensureGeoIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.ensureImageIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.ensureRefreshTokenIndexes(ctx)
+	if err != nil {
+		return err
+	}
+	err = m.dropPersonRefreshTokens(ctx)
+	if err != nil {
+		return err
+	}
+	return m.ensurePasswordResetIndexes(ctx)
+}
+
+// CreateAdvert add new advert to db
+func (m *MRepository) CreateAdvert(ctx context.Context, advert *model.Advert) (string, error) {
+	newID := uuid.New().String()
+	collection := m.MPool.Database("person").Collection("advert")
+	doc := bson.D{
+		{Key: "id", Value: newID},
+		{Key: "title", Value: advert.Title},
+		{Key: "description", Value: advert.Description},
+		{Key: "category", Value: advert.Category},
+		{Key: "address", Value: advert.Address},
+		{Key: "price", Value: advert.Price},
+		{Key: "currency", Value: advert.Currency},
+		{Key: "status", Value: advert.Status},
+		{Key: "ownerid", Value: advert.OwnerID},
+		{Key: "createdat", Value: creationTime(advert.CreatedAt)},
+		{Key: "updatedat", Value: creationTime(advert.UpdatedAt)},
+	}
+	if advert.Location != nil {
+		doc = append(doc, bson.E{Key: "location", Value: advert.Location})
+	}
+	_, err := collection.InsertOne(ctx, doc)
+	if err != nil {
+		return "", mongoError(err, "advert")
+	}
+	return newID, nil
+}
+
+// UpdateAdvert update exist advert
+func (m *MRepository) UpdateAdvert(ctx context.Context, id string, advert *model.Advert) error {
+	collection := m.MPool.Database("person").Collection("advert")
+	set := bson.D{
+		{Key: "title", Value: advert.Title},
+		{Key: "description", Value: advert.Description},
+		{Key: "category", Value: advert.Category},
+		{Key: "address", Value: advert.Address},
+		{Key: "price", Value: advert.Price},
+		{Key: "currency", Value: advert.Currency},
+		{Key: "updatedat", Value: creationTime(advert.UpdatedAt)},
+	}
+	if advert.Location != nil {
+		set = append(set, bson.E{Key: "location", Value: advert.Location})
+	}
+	update := bson.D{{Key: "$set", Value: set}}
+	if advert.Location == nil {
+		update = append(update, bson.E{Key: "$unset", Value: bson.D{{Key: "location", Value: ""}}})
+	}
+	res, err := collection.UpdateOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}, update)
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	if res.MatchedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "advert")
+	}
+	return nil
+}
+
+// SelectAdvertsByOwner take adverts created by user with this id in status, empty status means any
+func (m *MRepository) SelectAdvertsByOwner(ctx context.Context, ownerID, status string) ([]*model.Advert, error) {
+	var adverts []*model.Advert
+	collection := m.MPool.Database("person").Collection("advert")
+	filter := bson.D{primitive.E{Key: "ownerid", Value: ownerID}}
+	if status != "" {
+		filter = append(filter, primitive.E{Key: "status", Value: status})
+	}
+	c, err := collection.Find(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("mongo: unable to select adverts by owner %v", err)
+	}
+	defer func() {
+		_ = c.Close(ctx)
+	}()
+	for c.Next(ctx) {
+		advert := model.Advert{}
+		err := c.Decode(&advert)
+		if err != nil {
+			return nil, fmt.Errorf("mongo: unable to decode advert %v", err)
+		}
+		adverts = append(adverts, &advert)
+	}
+	return adverts, c.Err()
+}
+
+// SelectAdvertByID select exist advert from db by its id
+func (m *MRepository) SelectAdvertByID(ctx context.Context, id string) (model.Advert, error) {
+	advert := model.Advert{}
+	collection := m.MPool.Database("person").Collection("advert")
+	err := collection.FindOne(ctx, bson.D{primitive.E{Key: "id", Value: id}}).Decode(&advert)
+	if err != nil {
+		return model.Advert{}, mongoError(err, "advert")
+	}
+	return advert, nil
+}
+
+// DeleteAdvert delete advert from db
+func (m *MRepository) DeleteAdvert(ctx context.Context, id string) error {
+	collection := m.MPool.Database("person").Collection("advert")
+	res, err := collection.DeleteOne(ctx, bson.D{primitive.E{Key: "id", Value: id}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	if res.DeletedCount == 0 {
+		return mongoError(mongo.ErrNoDocuments, "advert")
+	}
+	return m.deleteAdvertImages(ctx, id)
+}
@@ -80,9 +80,9 @@ func TestSelectAllMongo(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	users, err := rps.rps.SelectAll(ctx)
+	page, err := rps.rps.SelectUsersPage(ctx, model.ListParams{Limit: model.MaxPageLimit})
 	require.NoError(t, err, "select all: problems with select all users")
-	require.Equal(t, 2, len(users), "select all: the values are`t equals")
+	require.Equal(t, 2, len(page.Persons), "select all: the values are`t equals")
 
 	collection := PoolM.Database("person").Collection("person")
 	_, err = collection.InsertOne(ctx, bson.D{
@@ -91,12 +91,12 @@ func TestSelectAllMongo(t *testing.T) {
 		{Key: "works", Value: true},
 		{Key: "age", Value: 18},
 		{Key: "password", Value: "sheisverybeatiful"},
-		{Key: "refreshtoken", Value: ""},
 	})
 	require.NoError(t, err, "select all: insert error")
-	users, err = rps.rps.SelectAll(ctx)
-	require.Equal(t, 3, len(users), "select all: the values are`t equals")
-	require.NotEqual(t, 4, len(users), "select all: the values are equals")
+	page, err = rps.rps.SelectUsersPage(ctx, model.ListParams{Limit: model.MaxPageLimit})
+	require.NoError(t, err, "select all: problems with select all users")
+	require.Equal(t, 3, len(page.Persons), "select all: the values are`t equals")
+	require.NotEqual(t, 4, len(page.Persons), "select all: the values are equals")
 
 }
 
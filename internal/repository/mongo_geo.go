@@ -0,0 +1,53 @@
+// Package repository : file contains nearby adverts in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ensureGeoIndexes create 2dsphere index of advert locations, adverts without location are not indexed
+func (m *MRepository) ensureGeoIndexes(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("advert").Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "location", Value: "2dsphere"}},
+	})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	return nil
+}
+
+// SelectNearbyAdverts select published adverts not farther than radius from point, nearest first
+func (m *MRepository) SelectNearbyAdverts(ctx context.Context, params model.NearbyParams) ([]model.AdvertDistance, error) {
+	collection := m.MPool.Database("person").Collection("advert")
+	c, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$geoNear", Value: bson.D{
+			{Key: "near", Value: params.Location},
+			{Key: "key", Value: "location"},
+			{Key: "distanceField", Value: "distance"},
+			{Key: "maxDistance", Value: params.Radius},
+			{Key: "spherical", Value: true},
+			{Key: "query", Value: bson.D{{Key: "status", Value: model.AdvertPublished}}},
+		}}},
+		{{Key: "$limit", Value: params.Limit}},
+	})
+	if err != nil {
+		return nil, mongoError(err, "advert")
+	}
+	var docs []struct {
+		model.Advert `bson:",inline"`
+		Distance     float64 `bson:"distance"`
+	}
+	err = c.All(ctx, &docs)
+	if err != nil {
+		return nil, mongoError(err, "advert")
+	}
+	found := make([]model.AdvertDistance, 0, len(docs))
+	for _, d := range docs {
+		found = append(found, model.AdvertDistance{Advert: d.Advert, Distance: d.Distance})
+	}
+	return found, nil
+}
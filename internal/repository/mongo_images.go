@@ -0,0 +1,95 @@
+// Package repository : file contains operations with advert images in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+
+	"github.com/labstack/gommon/log"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ensureImageIndexes create indexes for advert images
+func (m *MRepository) ensureImageIndexes(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("advertimage").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "advertid", Value: 1}, {Key: "createdat", Value: 1}}},
+	})
+	if err != nil {
+		return mongoError(err, "image")
+	}
+	return nil
+}
+
+// CreateImage add image of advert which has less than limit images to db, place for image is taken by
+// conditional increment of image counter of advert, so concurrent uploads cant exceed limit
+func (m *MRepository) CreateImage(ctx context.Context, image *model.Image, limit int) error {
+	adverts := m.MPool.Database("person").Collection("advert")
+	res, err := adverts.UpdateOne(ctx,
+		bson.D{{Key: "id", Value: image.AdvertID}, {Key: "imagecount", Value: bson.D{{Key: "$not", Value: bson.D{{Key: "$gte", Value: limit}}}}}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "imagecount", Value: 1}}}})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	if res.MatchedCount == 0 {
+		n, err := adverts.CountDocuments(ctx, bson.D{{Key: "id", Value: image.AdvertID}})
+		if err != nil {
+			return mongoError(err, "advert")
+		}
+		if n == 0 {
+			return mongoError(mongo.ErrNoDocuments, "advert")
+		}
+		return fmt.Errorf("advert already has %d images: %w", limit, model.ErrConflict)
+	}
+	stored := *image
+	stored.CreatedAt = creationTime(image.CreatedAt)
+	_, err = m.MPool.Database("person").Collection("advertimage").InsertOne(ctx, stored)
+	if err != nil {
+		_, undoErr := adverts.UpdateOne(ctx, bson.D{{Key: "id", Value: image.AdvertID}},
+			bson.D{{Key: "$inc", Value: bson.D{{Key: "imagecount", Value: -1}}}}) // give place back
+		if undoErr != nil {
+			log.Errorf("mongo: unable to release image place of advert %s, %v", image.AdvertID, undoErr)
+		}
+		return mongoError(err, "image")
+	}
+	return nil
+}
+
+// SelectImages select images of advert, oldest first
+func (m *MRepository) SelectImages(ctx context.Context, advertID string) ([]model.Image, error) {
+	c, err := m.MPool.Database("person").Collection("advertimage").Find(ctx,
+		bson.D{{Key: "advertid", Value: advertID}},
+		options.Find().SetSort(bson.D{{Key: "createdat", Value: 1}, {Key: "id", Value: 1}}))
+	if err != nil {
+		return nil, mongoError(err, "image")
+	}
+	images := make([]model.Image, 0)
+	err = c.All(ctx, &images)
+	if err != nil {
+		return nil, mongoError(err, "image")
+	}
+	return images, nil
+}
+
+// SelectImage select one image of advert by its id
+func (m *MRepository) SelectImage(ctx context.Context, advertID, id string) (model.Image, error) {
+	image := model.Image{}
+	err := m.MPool.Database("person").Collection("advertimage").FindOne(ctx,
+		bson.D{{Key: "advertid", Value: advertID}, {Key: "id", Value: id}}).Decode(&image)
+	if err != nil {
+		return model.Image{}, mongoError(err, "image")
+	}
+	return image, nil
+}
+
+// deleteAdvertImages delete images of deleted advert, postgres does it by foreign key
+func (m *MRepository) deleteAdvertImages(ctx context.Context, advertID string) error {
+	_, err := m.MPool.Database("person").Collection("advertimage").DeleteMany(ctx, bson.D{{Key: "advertid", Value: advertID}})
+	if err != nil {
+		return mongoError(err, "image")
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+// Package repository : file contains paginated lists in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"regexp"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ensureListIndexes create indexes for sorted lists and set creation time of documents created before it was stored
+func (m *MRepository) ensureListIndexes(ctx context.Context) error {
+	db := m.MPool.Database("person")
+	for collection, keys := range map[string][]string{"person": {"name", "createdat"}, "advert": {"title", "price", "createdat"}} {
+		models := make([]mongo.IndexModel, 0, len(keys))
+		for _, key := range keys {
+			models = append(models, mongo.IndexModel{Keys: bson.D{{Key: key, Value: 1}, {Key: "id", Value: 1}}})
+		}
+		_, err := db.Collection(collection).Indexes().CreateMany(ctx, models)
+		if err != nil {
+			return mongoError(err, collection)
+		}
+		_, err = db.Collection(collection).UpdateMany(ctx,
+			bson.D{{Key: "createdat", Value: bson.D{{Key: "$exists", Value: false}}}},
+			bson.D{{Key: "$currentDate", Value: bson.D{{Key: "createdat", Value: true}}}})
+		if err != nil {
+			return mongoError(err, collection)
+		}
+	}
+	return nil
+}
+
+// mongoPage add condition for documents after cursor to filter and return find options with sort, limit and skip
+func mongoPage(key string, params model.ListParams, filter bson.D) (bson.D, *options.FindOptions, error) {
+	direction, compare := 1, "$gt"
+	if params.Desc {
+		direction, compare = -1, "$lt"
+	}
+	skip := int64(params.Offset)
+	if params.Cursor != "" {
+		value, id, err := decodeCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return nil, nil, err
+		}
+		filter = append(filter, bson.E{Key: "$or", Value: bson.A{
+			bson.D{{Key: key, Value: bson.D{{Key: compare, Value: value}}}},
+			bson.D{{Key: key, Value: value}, {Key: "id", Value: bson.D{{Key: compare, Value: id}}}},
+		}})
+		skip = 0
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: key, Value: direction}, {Key: "id", Value: direction}}).
+		SetLimit(int64(params.Limit + 1)). // one more document tells if there is next page
+		SetSkip(skip)
+	return filter, opts, nil
+}
+
+// SelectUsersPage select page of users sorted by name or creation
+func (m *MRepository) SelectUsersPage(ctx context.Context, params model.ListParams) (model.UserPage, error) {
+	field, err := sortField(userSorts, &params)
+	if err != nil {
+		return model.UserPage{}, err
+	}
+	collection := m.MPool.Database("person").Collection("person")
+	page := model.UserPage{}
+	filter := bson.D{}
+	page.Total, err = collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return model.UserPage{}, mongoError(err, "user")
+	}
+	filter, opts, err := mongoPage(field[1], params, filter)
+	if err != nil {
+		return model.UserPage{}, err
+	}
+	opts.SetProjection(bson.D{{Key: "password", Value: 0}})
+	c, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return model.UserPage{}, mongoError(err, "user")
+	}
+	err = c.All(ctx, &page.Persons)
+	if err != nil {
+		return model.UserPage{}, mongoError(err, "user")
+	}
+	if len(page.Persons) > params.Limit {
+		page.Persons = page.Persons[:params.Limit]
+		page.NextCursor = userCursor(page.Persons[params.Limit-1], params.Sort)
+	}
+	return page, nil
+}
+
+// SelectAdvertsPage select page of adverts matched by filter sorted by price or creation
+func (m *MRepository) SelectAdvertsPage(ctx context.Context, params model.ListParams, filter model.AdvertFilter) (model.AdvertPage, error) {
+	field, err := sortField(advertSorts, &params)
+	if err != nil {
+		return model.AdvertPage{}, err
+	}
+	collection := m.MPool.Database("person").Collection("advert")
+	page := model.AdvertPage{}
+	query := bson.D{}
+	price := bson.D{}
+	if filter.MinPrice != nil {
+		price = append(price, bson.E{Key: "$gte", Value: *filter.MinPrice})
+	}
+	if filter.MaxPrice != nil {
+		price = append(price, bson.E{Key: "$lte", Value: *filter.MaxPrice})
+	}
+	if len(price) > 0 {
+		query = append(query, bson.E{Key: "price", Value: price})
+	}
+	if filter.Address != "" {
+		query = append(query, bson.E{Key: "address", Value: containsRegex(filter.Address)})
+	}
+	for key, value := range map[string]string{"category": filter.Category, "currency": filter.Currency, "status": filter.Status} {
+		if value != "" {
+			query = append(query, bson.E{Key: key, Value: value})
+		}
+	}
+	page.Total, err = collection.CountDocuments(ctx, query)
+	if err != nil {
+		return model.AdvertPage{}, mongoError(err, "advert")
+	}
+	query, opts, err := mongoPage(field[1], params, query)
+	if err != nil {
+		return model.AdvertPage{}, err
+	}
+	c, err := collection.Find(ctx, query, opts)
+	if err != nil {
+		return model.AdvertPage{}, mongoError(err, "advert")
+	}
+	err = c.All(ctx, &page.Adverts)
+	if err != nil {
+		return model.AdvertPage{}, mongoError(err, "advert")
+	}
+	if len(page.Adverts) > params.Limit {
+		page.Adverts = page.Adverts[:params.Limit]
+		page.NextCursor = advertCursor(page.Adverts[params.Limit-1], params.Sort)
+	}
+	return page, nil
+}
+
+// containsRegex case insensitive match of substring
+func containsRegex(substring string) bson.D {
+	return bson.D{{Key: "$regex", Value: regexp.QuoteMeta(substring)}, {Key: "$options", Value: "i"}}
+}
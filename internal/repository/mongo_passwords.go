@@ -0,0 +1,60 @@
+// Package repository : file contains operations with password reset tokens in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ensurePasswordResetIndexes create indexes for password resets, expired tokens are removed by mongo
+func (m *MRepository) ensurePasswordResetIndexes(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("passwordreset").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "tokenhash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expiresat", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return mongoError(err, "password reset")
+	}
+	return nil
+}
+
+// CreatePasswordReset add new password reset token to db
+func (m *MRepository) CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error {
+	collection := m.MPool.Database("person").Collection("passwordreset")
+	_, err := collection.InsertOne(ctx, reset)
+	if err != nil {
+		return mongoError(err, "password reset")
+	}
+	return nil
+}
+
+// UsePasswordReset mark not used and not expired password reset token as used and return it
+func (m *MRepository) UsePasswordReset(ctx context.Context, tokenHash string) (model.PasswordReset, error) {
+	reset := model.PasswordReset{}
+	now := time.Now()
+	collection := m.MPool.Database("person").Collection("passwordreset")
+	err := collection.FindOneAndUpdate(ctx,
+		bson.D{
+			{Key: "tokenhash", Value: tokenHash},
+			{Key: "usedat", Value: bson.D{{Key: "$exists", Value: false}}},
+			{Key: "expiresat", Value: bson.D{{Key: "$gt", Value: now}}},
+		},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "usedat", Value: now}}}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&reset)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return model.PasswordReset{}, fmt.Errorf("password reset token is invalid or expired: %w", model.ErrNotFound)
+		}
+		return model.PasswordReset{}, mongoError(err, "password reset")
+	}
+	return reset, nil
+}
@@ -0,0 +1,86 @@
+// Package repository : file contains full-text search of adverts in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/search"
+	"context"
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// names of text index of adverts, collection can have only one text index
+const (
+	advertSearchIndex       = "advert_text"
+	legacyAdvertSearchIndex = "advert_search" // address only
+)
+
+// mongo error codes of dropping index which is already dropped
+const (
+	mongoNamespaceNotFound = 26
+	mongoIndexNotFound     = 27
+)
+
+// ensureSearchIndexes replace address text index by weighted text index of adverts, words are not stemmed as in postgres
+func (m *MRepository) ensureSearchIndexes(ctx context.Context) error {
+	indexes := m.MPool.Database("person").Collection("advert").Indexes()
+	_, err := indexes.DropOne(ctx, legacyAdvertSearchIndex)
+	var cmdErr mongo.CommandError
+	if err != nil && !(errors.As(err, &cmdErr) && (cmdErr.Code == mongoNamespaceNotFound || cmdErr.Code == mongoIndexNotFound)) {
+		return mongoError(err, "advert")
+	}
+	_, err = indexes.CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "title", Value: "text"},
+			{Key: "description", Value: "text"},
+			{Key: "category", Value: "text"},
+			{Key: "address", Value: "text"},
+		},
+		Options: options.Index().
+			SetName(advertSearchIndex).
+			SetDefaultLanguage("none").
+			SetWeights(bson.D{{Key: "title", Value: 10}, {Key: "category", Value: 4}, {Key: "address", Value: 4}, {Key: "description", Value: 2}}),
+	})
+	if err != nil {
+		return mongoError(err, "advert")
+	}
+	return nil
+}
+
+// SearchAdverts find published adverts with any word of query ranked by mongo text score
+func (m *MRepository) SearchAdverts(ctx context.Context, query string, limit int) ([]model.AdvertHit, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return []model.AdvertHit{}, nil
+	}
+	score := bson.D{{Key: "$meta", Value: "textScore"}}
+	opts := options.Find().
+		SetProjection(bson.D{{Key: "score", Value: score}}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "id", Value: 1}}).
+		SetLimit(int64(limit))
+	collection := m.MPool.Database("person").Collection("advert")
+	c, err := collection.Find(ctx, bson.D{
+		{Key: "$text", Value: bson.D{{Key: "$search", Value: strings.Join(terms, " ")}}},
+		{Key: "status", Value: model.AdvertPublished},
+	}, opts)
+	if err != nil {
+		return nil, mongoError(err, "advert")
+	}
+	var found []struct {
+		model.Advert `bson:",inline"`
+		Score        float64 `bson:"score"`
+	}
+	err = c.All(ctx, &found)
+	if err != nil {
+		return nil, mongoError(err, "advert")
+	}
+	hits := make([]model.AdvertHit, 0, len(found))
+	for _, f := range found {
+		hits = append(hits, model.AdvertHit{Advert: f.Advert, Rank: f.Score, Highlights: search.HighlightAdvert(f.Advert, terms)})
+	}
+	return hits, nil
+}
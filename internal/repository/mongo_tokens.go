@@ -0,0 +1,154 @@
+// Package repository : file contains operations with refresh tokens in MongoDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ensureRefreshTokenIndexes create indexes for refresh tokens, expired tokens are removed by mongo
+func (m *MRepository) ensureRefreshTokenIndexes(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("refreshtoken").Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "tokenhash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "familyid", Value: 1}}},
+		{Keys: bson.D{{Key: "userid", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresat", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return mongoError(err, "refresh token")
+	}
+	return nil
+}
+
+// dropPersonRefreshTokens remove refresh token field left from users which had only one session
+func (m *MRepository) dropPersonRefreshTokens(ctx context.Context) error {
+	_, err := m.MPool.Database("person").Collection("person").UpdateMany(ctx,
+		bson.D{{Key: "refreshtoken", Value: bson.D{{Key: "$exists", Value: true}}}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "refreshtoken", Value: ""}}}})
+	if err != nil {
+		return mongoError(err, "user")
+	}
+	return nil
+}
+
+// CreateRefreshToken add new refresh token to db
+func (m *MRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	_, err := collection.InsertOne(ctx, token)
+	if err != nil {
+		return mongoError(err, "refresh token")
+	}
+	return nil
+}
+
+// SelectRefreshToken select refresh token by hash of its value
+func (m *MRepository) SelectRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	token := model.RefreshToken{}
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	err := collection.FindOne(ctx, bson.D{primitive.E{Key: "tokenhash", Value: tokenHash}}).Decode(&token)
+	if err != nil {
+		return model.RefreshToken{}, mongoError(err, "refresh token")
+	}
+	return token, nil
+}
+
+// UseRefreshToken mark refresh token as used, returns false if it was already used before
+func (m *MRepository) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	res, err := collection.UpdateOne(ctx,
+		bson.D{{Key: "id", Value: id}, {Key: "usedat", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "usedat", Value: time.Now()}}}})
+	if err != nil {
+		return false, mongoError(err, "refresh token")
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+// RevokeRefreshTokenFamily revoke all refresh tokens issued for one login
+func (m *MRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return m.revokeRefreshTokens(ctx, primitive.E{Key: "familyid", Value: familyID})
+}
+
+// RevokeUserRefreshTokens revoke all refresh tokens of user
+func (m *MRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	return m.revokeRefreshTokens(ctx, primitive.E{Key: "userid", Value: userID})
+}
+
+// revokeRefreshTokens revoke not revoked yet refresh tokens matched by filter
+func (m *MRepository) revokeRefreshTokens(ctx context.Context, filter primitive.E) error {
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	_, err := collection.UpdateMany(ctx,
+		bson.D{filter, {Key: "revokedat", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revokedat", Value: time.Now()}}}})
+	if err != nil {
+		return mongoError(err, "refresh token")
+	}
+	return nil
+}
+
+// SelectSessions select active sessions of user, session is described by its last not used refresh token
+func (m *MRepository) SelectSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "userid", Value: userID}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "createdat", Value: 1}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$familyid"},
+			{Key: "createdat", Value: bson.D{{Key: "$first", Value: "$createdat"}}},
+			{Key: "last", Value: bson.D{{Key: "$last", Value: "$$ROOT"}}},
+		}}},
+		{{Key: "$match", Value: bson.D{
+			{Key: "last.usedat", Value: bson.D{{Key: "$exists", Value: false}}},
+			{Key: "last.revokedat", Value: bson.D{{Key: "$exists", Value: false}}},
+			{Key: "last.expiresat", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "last.createdat", Value: -1}}}},
+	})
+	if err != nil {
+		return nil, mongoError(err, "session")
+	}
+	defer cursor.Close(ctx)
+	sessions := []model.Session{}
+	for cursor.Next(ctx) {
+		var group struct {
+			CreatedAt time.Time          `bson:"createdat"`
+			Last      model.RefreshToken `bson:"last"`
+		}
+		err = cursor.Decode(&group)
+		if err != nil {
+			return nil, mongoError(err, "session")
+		}
+		sessions = append(sessions, model.Session{
+			ID:         group.Last.FamilyID,
+			CreatedAt:  group.CreatedAt,
+			LastUsedAt: group.Last.CreatedAt,
+			ExpiresAt:  group.Last.ExpiresAt,
+			UserAgent:  group.Last.UserAgent,
+			IP:         group.Last.IP,
+		})
+	}
+	return sessions, cursor.Err()
+}
+
+// RevokeSession revoke refresh tokens of one session of user
+func (m *MRepository) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	collection := m.MPool.Database("person").Collection("refreshtoken")
+	res, err := collection.UpdateMany(ctx,
+		bson.D{{Key: "userid", Value: userID}, {Key: "familyid", Value: sessionID},
+			{Key: "revokedat", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revokedat", Value: time.Now()}}}})
+	if err != nil {
+		return mongoError(err, "session")
+	}
+	if res.ModifiedCount == 0 {
+		return notFound("session")
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+// Package repository : file contains keyset cursors shared by all DBs
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// pageCursor sort value and id of last item of page, next page starts right after it
+type pageCursor struct {
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+// encodeCursor make opaque cursor for client
+func encodeCursor(value, id string) string {
+	raw, _ := json.Marshal(pageCursor{Value: value, ID: id})
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor read cursor from client and parse its value by sort field
+func decodeCursor(cursor, sort string) (value interface{}, id string, err error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, "", fmt.Errorf("cursor is malformed: %w", model.ErrValidation)
+	}
+	c := pageCursor{}
+	err = json.Unmarshal(raw, &c)
+	if err != nil || c.ID == "" {
+		return nil, "", fmt.Errorf("cursor is malformed: %w", model.ErrValidation)
+	}
+	switch sort {
+	case model.SortName, model.SortTitle:
+		value = c.Value
+	case model.SortPrice:
+		value, err = model.ParsePrice(c.Value)
+	case model.SortCreated:
+		value, err = time.Parse(time.RFC3339Nano, c.Value)
+	default:
+		err = fmt.Errorf("unknown sort %s", sort)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("cursor doesnt match sort, %v: %w", err, model.ErrValidation)
+	}
+	return value, c.ID, nil
+}
+
+// userCursor cursor pointing after user
+func userCursor(p *model.Person, sort string) string {
+	if sort == model.SortName {
+		return encodeCursor(p.Name, p.ID)
+	}
+	return encodeCursor(p.CreatedAt.Format(time.RFC3339Nano), p.ID)
+}
+
+// advertCursor cursor pointing after advert
+func advertCursor(a *model.Advert, sort string) string {
+	switch sort {
+	case model.SortTitle:
+		return encodeCursor(a.Title, a.ID)
+	case model.SortPrice:
+		return encodeCursor(a.Price.String(), a.ID)
+	}
+	return encodeCursor(a.CreatedAt.Format(time.RFC3339Nano), a.ID)
+}
+
+// userSorts fields users can be sorted by with their postgres columns and mongo keys
+var userSorts = map[string][2]string{
+	model.SortName:    {"name", "name"},
+	model.SortCreated: {"created_at", "createdat"},
+}
+
+// advertSorts fields adverts can be sorted by with their postgres columns and mongo keys
+var advertSorts = map[string][2]string{
+	model.SortTitle:   {"title", "title"},
+	model.SortPrice:   {"price", "price"},
+	model.SortCreated: {"created_at", "createdat"},
+}
+
+// sortField find postgres column and mongo key of sort, empty sort means sort by creation
+func sortField(sorts map[string][2]string, params *model.ListParams) ([2]string, error) {
+	if params.Sort == "" {
+		params.Sort = model.SortCreated
+	}
+	field, ok := sorts[params.Sort]
+	if !ok {
+		return [2]string{}, fmt.Errorf("list cant be sorted by %s: %w", params.Sort, model.ErrValidation)
+	}
+	return field, nil
+}
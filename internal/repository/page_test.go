@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCursor(t *testing.T) {
+	created := time.Date(2022, 8, 1, 10, 30, 0, 123456000, time.UTC)
+	advert := &model.Advert{ID: "a20fc586-d9d2-4969-909f-d00bf42aa88a", Price: 1010, CreatedAt: created}
+	value, id, err := decodeCursor(advertCursor(advert, model.SortPrice), model.SortPrice)
+	require.NoError(t, err)
+	require.Equal(t, model.Price(1010), value)
+	require.Equal(t, advert.ID, id)
+
+	value, _, err = decodeCursor(advertCursor(advert, model.SortCreated), model.SortCreated)
+	require.NoError(t, err)
+	require.True(t, created.Equal(value.(time.Time)))
+
+	advert.Title = "Flat in Minsk"
+	value, _, err = decodeCursor(advertCursor(advert, model.SortTitle), model.SortTitle)
+	require.NoError(t, err)
+	require.Equal(t, "Flat in Minsk", value)
+
+	person := &model.Person{ID: advert.ID, Name: "Egor Tihonov"}
+	value, _, err = decodeCursor(userCursor(person, model.SortName), model.SortName)
+	require.NoError(t, err)
+	require.Equal(t, "Egor Tihonov", value)
+
+	_, _, err = decodeCursor("not a cursor", model.SortName)
+	require.True(t, errors.Is(err, model.ErrValidation))
+	_, _, err = decodeCursor(userCursor(person, model.SortName), model.SortCreated)
+	require.True(t, errors.Is(err, model.ErrValidation), "name cursor is used for created sort")
+}
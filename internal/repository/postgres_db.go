@@ -4,91 +4,114 @@ package repository
 import (
 	"awesomeProject/internal/model"
 	"context"
+	"errors"
 	"fmt"
-	"github.com/jackc/pgx/v4"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"github.com/labstack/gommon/log"
 )
 
+// postgres error codes which are mapped to domain errors
+const (
+	pgUniqueViolation     = "23505"
+	pgForeignKeyViolation = "23503"
+	pgCheckViolation      = "23514"
+	pgInvalidText         = "22P02"
+)
+
 // PRepository :creating new connection with PostgresDB
 type PRepository struct {
 	PPool *pgxpool.Pool
 }
 
+// pgError wrap postgres error about entity into domain error
+func pgError(err error, entity string) error {
+	if errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("%s with this id doesnt exist: %w", entity, model.ErrNotFound)
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgUniqueViolation:
+			return fmt.Errorf("%s already exists, %s: %w", entity, pgErr.Detail, model.ErrConflict)
+		case pgForeignKeyViolation, pgCheckViolation:
+			return fmt.Errorf("%s is invalid, %s: %w", entity, pgErr.Message, model.ErrValidation)
+		case pgInvalidText:
+			return fmt.Errorf("%s with this id doesnt exist: %w", entity, model.ErrNotFound)
+		}
+	}
+	return err
+}
+
+// notFound error when no rows were affected by query
+func notFound(entity string) error {
+	return fmt.Errorf("%s with this id doesnt exist: %w", entity, model.ErrNotFound)
+}
+
 // Create : insert new user into database
 func (r *PRepository) Create(ctx context.Context, person *model.Person) (string, error) {
 	newID := uuid.New().String()
-	_, err := r.PPool.Exec(ctx, "insert into persons(id,name,password) values($1,$2,$3)",
-		newID, &person.Name, &person.Password)
+	_, err := r.PPool.Exec(ctx, "insert into persons(id,name,password,roles,created_at) values($1,$2,$3,coalesce($4,'{user}'::text[]),$5)",
+		newID, &person.Name, &person.Password, person.Roles, creationTime(person.CreatedAt))
 	if err != nil {
 		log.Errorf("database error with create user: %v", err)
-		return "", err
+		return "", pgError(err, "user")
 	}
 	return newID, nil
 }
 
-// SelectAll : Print all users(ID,Name,Works) from database
-func (r *PRepository) SelectAll(ctx context.Context) ([]*model.Person, error) {
-	var persons []*model.Person
-	rows, err := r.PPool.Query(ctx, "select id,name from persons")
-	if err != nil {
-		log.Errorf("database error with select all users, %v", err)
-		return nil, err
-	}
-	defer rows.Close()
-	for rows.Next() {
-		p := model.Person{}
-		err := rows.Scan(&p.ID, &p.Name)
-		if err != nil {
-			log.Errorf("database error with select all users, %v", err)
-			return nil, err
-		}
-		persons = append(persons, &p)
-	}
-
-	return persons, nil
-}
-
 // Delete : delete user by his ID
 func (r *PRepository) Delete(ctx context.Context, id string) error {
 	a, err := r.PPool.Exec(ctx, "delete from persons where id=$1", id)
-	if a.RowsAffected() == 0 {
-		return fmt.Errorf("user with this id doesnt exist")
-	}
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("user with this id doesnt exist: %v", err)
-		}
 		log.Errorf("error with delete user %v", err)
-		return err
+		return pgError(err, "user")
+	}
+	if a.RowsAffected() == 0 {
+		return notFound("user")
 	}
 	return nil
 }
 
-// UpdateAuth : update user refreshToken by his ID
-func (r *PRepository) UpdateAuth(ctx context.Context, id, refreshToken string) error {
-	a, err := r.PPool.Exec(ctx, "update persons set refreshToken=$1 where id=$2", refreshToken, id)
-	if a.RowsAffected() == 0 {
-		return fmt.Errorf("user with this id doesnt exist")
-	}
+// Update update user in db
+func (r *PRepository) Update(ctx context.Context, id string, p *model.Person) error {
+	a, err := r.PPool.Exec(ctx, "update persons set name=$1 where id=$2", &p.Name, id)
 	if err != nil {
 		log.Errorf("error with update user %v", err)
-		return err
+		return pgError(err, "user")
+	}
+	if a.RowsAffected() == 0 {
+		return notFound("user")
 	}
 	return nil
 }
 
-// Update update user in db
-func (r *PRepository) Update(ctx context.Context, id string, p *model.Person) error {
-	a, err := r.PPool.Exec(ctx, "update persons set name=$1 where id=$2", &p.Name, id)
+// UpdateRoles : replace roles of user
+func (r *PRepository) UpdateRoles(ctx context.Context, id string, roles []string) error {
+	a, err := r.PPool.Exec(ctx, "update persons set roles=$1 where id=$2", roles, id)
+	if err != nil {
+		log.Errorf("error with update user roles %v", err)
+		return pgError(err, "user")
+	}
 	if a.RowsAffected() == 0 {
-		return fmt.Errorf("user with this id doesnt exist")
+		return notFound("user")
 	}
+	return nil
+}
+
+// UpdatePassword : replace password hash of user
+func (r *PRepository) UpdatePassword(ctx context.Context, id, password string) error {
+	a, err := r.PPool.Exec(ctx, "update persons set password=$1 where id=$2", password, id)
 	if err != nil {
-		log.Errorf("error with update user %v", err)
-		return err
+		log.Errorf("error with update user password %v", err)
+		return pgError(err, "user")
+	}
+	if a.RowsAffected() == 0 {
+		return notFound("user")
 	}
 	return nil
 }
@@ -96,88 +119,141 @@ func (r *PRepository) Update(ctx context.Context, id string, p *model.Person) er
 // SelectByID : select one user by his ID
 func (r *PRepository) SelectByID(ctx context.Context, id string) (model.Person, error) {
 	p := model.Person{}
-	err := r.PPool.QueryRow(ctx, "select id,name,password from persons where id=$1", id).Scan(
-		&p.ID, &p.Name, &p.Password)
+	err := r.PPool.QueryRow(ctx, "select id,name,password,roles,created_at from persons where id=$1", id).Scan(
+		&p.ID, &p.Name, &p.Password, &p.Roles, &p.CreatedAt)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return model.Person{}, fmt.Errorf("user with this id doesnt exist: %v", err)
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, select by id: %v", err)
 		}
-		log.Errorf("database error, select by id: %v", err)
-		return model.Person{}, err /*p, fmt.errorf("user with this id doesn't exist")*/
+		return model.Person{}, pgError(err, "user")
 	}
 	return p, nil
 }
 
-// SelectByIDAuth select auth user
-func (r *PRepository) SelectByIDAuth(ctx context.Context, id string) (model.Person, error) {
+// SelectByName : select one user by his unique name
+func (r *PRepository) SelectByName(ctx context.Context, name string) (model.Person, error) {
 	p := model.Person{}
-	err := r.PPool.QueryRow(ctx, "select id,refreshToken from persons where id=$1", id).Scan(&p.ID, &p.RefreshToken)
-
-	if err != nil /*err==no-records*/ {
-		if err == pgx.ErrNoRows {
-			return model.Person{}, fmt.Errorf("user with this id doesnt exist: %v", err)
+	err := r.PPool.QueryRow(ctx, "select id,name,password,roles,created_at from persons where name=$1", name).Scan(
+		&p.ID, &p.Name, &p.Password, &p.Roles, &p.CreatedAt)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, select by name: %v", err)
+			return model.Person{}, pgError(err, "user")
 		}
-		log.Errorf("database error, select by id: %v", err)
-		return model.Person{}, err /*p, fmt.errorf("user with this id doesn't exist")*/
+		return model.Person{}, fmt.Errorf("user with this name doesnt exist: %w", model.ErrNotFound)
 	}
 	return p, nil
 }
 
+// advertColumns columns of adverts in order of scanAdvert
+const advertColumns = "id,title,description,category,address,price,currency,status,owner_id,created_at,updated_at,lat,lon"
+
+// scanAdvert read advert selected with advertColumns, extra values selected after them are read into extra
+func scanAdvert(row pgx.Row, extra ...interface{}) (model.Advert, error) {
+	a := model.Advert{}
+	var lat, lon *float64
+	err := row.Scan(append([]interface{}{&a.ID, &a.Title, &a.Description, &a.Category, &a.Address, &a.Price, &a.Currency,
+		&a.Status, &a.OwnerID, &a.CreatedAt, &a.UpdatedAt, &lat, &lon}, extra...)...)
+	if lat != nil && lon != nil {
+		a.Location = &model.Location{Lat: *lat, Lon: *lon}
+	}
+	return a, err
+}
+
+// locationArgs latitude and longitude of location as query arguments, nil location is stored as nulls
+func locationArgs(l *model.Location) (lat, lon interface{}) {
+	if l == nil {
+		return nil, nil
+	}
+	return l.Lat, l.Lon
+}
+
 func (r *PRepository) CreateAdvert(ctx context.Context, advert *model.Advert) (string, error) {
 	newID := uuid.New().String()
-	_, err := r.PPool.Exec(ctx, "insert into advert(id,address,price) values($1,$2,$3)",
-		newID, &advert.Address, &advert.Price)
+	lat, lon := locationArgs(advert.Location)
+	_, err := r.PPool.Exec(ctx, "insert into adverts("+advertColumns+") values($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)",
+		newID, advert.Title, advert.Description, advert.Category, advert.Address, advert.Price, advert.Currency,
+		advert.Status, advert.OwnerID, creationTime(advert.CreatedAt), creationTime(advert.UpdatedAt), lat, lon)
 	if err != nil {
 		log.Errorf("database error with create advert: %v", err)
-		return "", err
+		return "", pgError(err, "advert")
 	}
 	return newID, nil
 }
 
-func (r *PRepository) SelectAllAdvert(ctx context.Context) ([]*model.Advert, error) {
+// SelectAdvertsByOwner : select adverts created by user with this ID in status, empty status means any
+func (r *PRepository) SelectAdvertsByOwner(ctx context.Context, ownerID, status string) ([]*model.Advert, error) {
 	var adverts []*model.Advert
-	rows, err := r.PPool.Query(ctx, "select id,address,price from adverts")
+	rows, err := r.PPool.Query(ctx, "select "+advertColumns+" from adverts where owner_id=$1 and ($2='' or status=$2)",
+		ownerID, status)
 	if err != nil {
-		log.Errorf("database error with select all adverts, %v", err)
+		log.Errorf("database error with select adverts by owner, %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 	for rows.Next() {
-		advert := model.Advert{}
-		err := rows.Scan(&advert.ID, &advert.Address, &advert.Price)
+		advert, err := scanAdvert(rows)
 		if err != nil {
-			log.Errorf("database error with select all adverts, %v", err)
+			log.Errorf("database error with select adverts by owner, %v", err)
 			return nil, err
 		}
 		adverts = append(adverts, &advert)
 	}
-
+	if err = rows.Err(); err != nil {
+		log.Errorf("database error with select adverts by owner, %v", err)
+		return nil, pgError(err, "advert")
+	}
 	return adverts, nil
 }
 
 func (r *PRepository) DeleteAdvert(ctx context.Context, id string) error {
 	a, err := r.PPool.Exec(ctx, "delete from adverts where id=$1", id)
-	if a.RowsAffected() == 0 {
-		return fmt.Errorf("advert with this id doesnt exist")
-	}
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("advert with this id doesnt exist: %v", err)
-		}
 		log.Errorf("error with delete advert %v", err)
-		return err
+		return pgError(err, "advert")
+	}
+	if a.RowsAffected() == 0 {
+		return notFound("advert")
 	}
 	return nil
 }
 
 func (r *PRepository) UpdateAdvert(ctx context.Context, id string, advert *model.Advert) error {
-	a, err := r.PPool.Exec(ctx, "update adverts set address=$1,price=$2 where id=$4", &advert.Address, &advert.Price, id)
+	lat, lon := locationArgs(advert.Location)
+	a, err := r.PPool.Exec(ctx, `update adverts set title=$1,description=$2,category=$3,address=$4,price=$5,currency=$6,updated_at=$7,
+		lat=$8,lon=$9 where id=$10`, advert.Title, advert.Description, advert.Category, advert.Address, advert.Price, advert.Currency,
+		creationTime(advert.UpdatedAt), lat, lon, id)
+	if err != nil {
+		log.Errorf("error with update advert %v", err)
+		return pgError(err, "advert")
+	}
 	if a.RowsAffected() == 0 {
-		return fmt.Errorf("user with this id doesnt exist")
+		return notFound("advert")
 	}
+	return nil
+}
+
+// UpdateAdvertStatus : move advert from status to another one, advert which status was changed by someone else is conflict
+func (r *PRepository) UpdateAdvertStatus(ctx context.Context, id, from, to string, updatedAt time.Time) error {
+	a, err := r.PPool.Exec(ctx, "update adverts set status=$1,updated_at=$2 where id=$3 and status=$4", to, updatedAt, id, from)
 	if err != nil {
-		log.Errorf("error with update user %v", err)
-		return err
+		log.Errorf("error with update advert status %v", err)
+		return pgError(err, "advert")
+	}
+	if a.RowsAffected() == 0 {
+		return fmt.Errorf("advert isnt %s anymore: %w", from, model.ErrConflict)
 	}
 	return nil
 }
+
+// SelectAdvertByID : select one advert by its ID
+func (r *PRepository) SelectAdvertByID(ctx context.Context, id string) (model.Advert, error) {
+	advert, err := scanAdvert(r.PPool.QueryRow(ctx, "select "+advertColumns+" from adverts where id=$1", id))
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, select advert by id: %v", err)
+		}
+		return model.Advert{}, pgError(err, "advert")
+	}
+	return advert, nil
+}
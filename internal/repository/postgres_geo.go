@@ -0,0 +1,46 @@
+// Package repository : file contains nearby adverts in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+
+	"github.com/labstack/gommon/log"
+)
+
+// SelectNearbyAdverts : select published adverts not farther than radius from point, nearest first,
+// rows are found by bounding box on lat,lon index and then checked by haversine distance
+func (r *PRepository) SelectNearbyAdverts(ctx context.Context, params model.NearbyParams) ([]model.AdvertDistance, error) {
+	box := boundingBox(params.Location, params.Radius)
+	q := pgQuery{}
+	lat, lon := q.arg(params.Lat)+"::float8", q.arg(params.Lon)+"::float8"
+	q.conditions = append(q.conditions, "status="+q.arg(model.AdvertPublished),
+		"lat between "+q.arg(box.MinLat)+" and "+q.arg(box.MaxLat))
+	if box.LonBounded {
+		q.conditions = append(q.conditions, "lon between "+q.arg(box.MinLon)+" and "+q.arg(box.MaxLon))
+	}
+	distance := "2*" + q.arg(earthRadius) + "::float8*asin(least(1,sqrt(power(sin(radians(lat-" + lat + ")/2),2)+" +
+		"cos(radians(" + lat + "))*cos(radians(lat))*power(sin(radians(lon-" + lon + ")/2),2))))"
+	rows, err := r.PPool.Query(ctx, "select "+advertColumns+",distance from (select "+advertColumns+","+distance+
+		" distance from adverts"+q.where()+") nearby where distance<="+q.arg(params.Radius)+
+		" order by distance, id limit "+q.arg(params.Limit), q.args...)
+	if err != nil {
+		log.Errorf("database error with select nearby adverts, %v", err)
+		return nil, pgError(err, "advert")
+	}
+	defer rows.Close()
+	found := make([]model.AdvertDistance, 0)
+	for rows.Next() {
+		f := model.AdvertDistance{}
+		f.Advert, err = scanAdvert(rows, &f.Distance)
+		if err != nil {
+			log.Errorf("database error with select nearby adverts, %v", err)
+			return nil, pgError(err, "advert")
+		}
+		found = append(found, f)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, pgError(err, "advert")
+	}
+	return found, nil
+}
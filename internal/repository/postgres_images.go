@@ -0,0 +1,86 @@
+// Package repository : file contains operations with advert images in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// imageColumns columns of advert images in order of scanImage
+const imageColumns = "id,advert_id,content_type,size,width,height,created_at"
+
+// scanImage read image selected with imageColumns
+func scanImage(row pgx.Row) (model.Image, error) {
+	i := model.Image{}
+	err := row.Scan(&i.ID, &i.AdvertID, &i.ContentType, &i.Size, &i.Width, &i.Height, &i.CreatedAt)
+	return i, err
+}
+
+// CreateImage : insert image of advert which has less than limit images, advert row is locked
+// so concurrent uploads are counted one after another
+func (r *PRepository) CreateImage(ctx context.Context, image *model.Image, limit int) error {
+	err := r.PPool.BeginFunc(ctx, func(tx pgx.Tx) error {
+		tag, err := tx.Exec(ctx, "select id from adverts where id=$1 for update", image.AdvertID)
+		if err != nil {
+			return err
+		}
+		if tag.RowsAffected() == 0 {
+			return notFound("advert")
+		}
+		var count int
+		err = tx.QueryRow(ctx, "select count(*) from advert_images where advert_id=$1", image.AdvertID).Scan(&count)
+		if err != nil {
+			return err
+		}
+		if count >= limit {
+			return fmt.Errorf("advert already has %d images: %w", count, model.ErrConflict)
+		}
+		_, err = tx.Exec(ctx, "insert into advert_images("+imageColumns+") values($1,$2,$3,$4,$5,$6,$7)",
+			image.ID, image.AdvertID, image.ContentType, image.Size, image.Width, image.Height, creationTime(image.CreatedAt))
+		return err
+	})
+	if err != nil {
+		log.Errorf("database error with create image: %v", err)
+		return pgError(err, "image")
+	}
+	return nil
+}
+
+// SelectImages : select images of advert, oldest first
+func (r *PRepository) SelectImages(ctx context.Context, advertID string) ([]model.Image, error) {
+	rows, err := r.PPool.Query(ctx, "select "+imageColumns+" from advert_images where advert_id=$1 order by created_at, id", advertID)
+	if err != nil {
+		log.Errorf("database error with select images, %v", err)
+		return nil, pgError(err, "image")
+	}
+	defer rows.Close()
+	images := make([]model.Image, 0)
+	for rows.Next() {
+		image, err := scanImage(rows)
+		if err != nil {
+			log.Errorf("database error with select images, %v", err)
+			return nil, pgError(err, "image")
+		}
+		images = append(images, image)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, pgError(err, "image")
+	}
+	return images, nil
+}
+
+// SelectImage : select one image of advert by its ID
+func (r *PRepository) SelectImage(ctx context.Context, advertID, id string) (model.Image, error) {
+	image, err := scanImage(r.PPool.QueryRow(ctx, "select "+imageColumns+" from advert_images where advert_id=$1 and id=$2", advertID, id))
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, select image by id: %v", err)
+		}
+		return model.Image{}, pgError(err, "image")
+	}
+	return image, nil
+}
@@ -0,0 +1,156 @@
+// Package repository : file contains paginated lists in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+// pgQuery collects conditions and arguments of select
+type pgQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg add argument and return its placeholder
+func (q *pgQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// where build where clause from conditions
+func (q *pgQuery) where() string {
+	if len(q.conditions) == 0 {
+		return ""
+	}
+	return " where " + strings.Join(q.conditions, " and ")
+}
+
+// page add condition for rows after cursor and return order by clause with limit and offset
+func (q *pgQuery) page(column string, params model.ListParams) (string, error) {
+	direction, compare := "asc", ">"
+	if params.Desc {
+		direction, compare = "desc", "<"
+	}
+	offset := params.Offset
+	if params.Cursor != "" {
+		value, id, err := decodeCursor(params.Cursor, params.Sort)
+		if err != nil {
+			return "", err
+		}
+		q.conditions = append(q.conditions, fmt.Sprintf("(%s,id) %s (%s,%s::uuid)", column, compare, q.arg(value), q.arg(id)))
+		offset = 0
+	}
+	return fmt.Sprintf(" order by %s %s, id %s limit %s offset %s",
+		column, direction, direction, q.arg(params.Limit+1), q.arg(offset)), nil // one more row tells if there is next page
+}
+
+// escapeLike escape wildcards of like pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// SelectUsersPage : select page of users sorted by name or creation
+func (r *PRepository) SelectUsersPage(ctx context.Context, params model.ListParams) (model.UserPage, error) {
+	field, err := sortField(userSorts, &params)
+	if err != nil {
+		return model.UserPage{}, err
+	}
+	page := model.UserPage{}
+	q := pgQuery{}
+	err = r.PPool.QueryRow(ctx, "select count(*) from persons"+q.where(), q.args...).Scan(&page.Total)
+	if err != nil {
+		log.Errorf("database error with count users, %v", err)
+		return model.UserPage{}, pgError(err, "user")
+	}
+	tail, err := q.page(field[0], params)
+	if err != nil {
+		return model.UserPage{}, err
+	}
+	rows, err := r.PPool.Query(ctx, "select id,name,roles,created_at from persons"+q.where()+tail, q.args...)
+	if err != nil {
+		log.Errorf("database error with select users page, %v", err)
+		return model.UserPage{}, pgError(err, "user")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		p := model.Person{}
+		err = rows.Scan(&p.ID, &p.Name, &p.Roles, &p.CreatedAt)
+		if err != nil {
+			log.Errorf("database error with select users page, %v", err)
+			return model.UserPage{}, pgError(err, "user")
+		}
+		page.Persons = append(page.Persons, &p)
+	}
+	if err = rows.Err(); err != nil {
+		return model.UserPage{}, pgError(err, "user")
+	}
+	if len(page.Persons) > params.Limit {
+		page.Persons = page.Persons[:params.Limit]
+		page.NextCursor = userCursor(page.Persons[params.Limit-1], params.Sort)
+	}
+	return page, nil
+}
+
+// SelectAdvertsPage : select page of adverts matched by filter sorted by price or creation
+func (r *PRepository) SelectAdvertsPage(ctx context.Context, params model.ListParams, filter model.AdvertFilter) (model.AdvertPage, error) {
+	field, err := sortField(advertSorts, &params)
+	if err != nil {
+		return model.AdvertPage{}, err
+	}
+	page := model.AdvertPage{}
+	q := pgQuery{}
+	if filter.MinPrice != nil {
+		q.conditions = append(q.conditions, "price>="+q.arg(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		q.conditions = append(q.conditions, "price<="+q.arg(*filter.MaxPrice))
+	}
+	if filter.Address != "" {
+		q.conditions = append(q.conditions, "address ilike '%' || "+q.arg(escapeLike(filter.Address))+" || '%'")
+	}
+	if filter.Category != "" {
+		q.conditions = append(q.conditions, "category="+q.arg(filter.Category))
+	}
+	if filter.Currency != "" {
+		q.conditions = append(q.conditions, "currency="+q.arg(filter.Currency))
+	}
+	if filter.Status != "" {
+		q.conditions = append(q.conditions, "status="+q.arg(filter.Status))
+	}
+	err = r.PPool.QueryRow(ctx, "select count(*) from adverts"+q.where(), q.args...).Scan(&page.Total)
+	if err != nil {
+		log.Errorf("database error with count adverts, %v", err)
+		return model.AdvertPage{}, pgError(err, "advert")
+	}
+	tail, err := q.page(field[0], params)
+	if err != nil {
+		return model.AdvertPage{}, err
+	}
+	rows, err := r.PPool.Query(ctx, "select "+advertColumns+" from adverts"+q.where()+tail, q.args...)
+	if err != nil {
+		log.Errorf("database error with select adverts page, %v", err)
+		return model.AdvertPage{}, pgError(err, "advert")
+	}
+	defer rows.Close()
+	for rows.Next() {
+		advert, err := scanAdvert(rows)
+		if err != nil {
+			log.Errorf("database error with select adverts page, %v", err)
+			return model.AdvertPage{}, pgError(err, "advert")
+		}
+		page.Adverts = append(page.Adverts, &advert)
+	}
+	if err = rows.Err(); err != nil {
+		return model.AdvertPage{}, pgError(err, "advert")
+	}
+	if len(page.Adverts) > params.Limit {
+		page.Adverts = page.Adverts[:params.Limit]
+		page.NextCursor = advertCursor(page.Adverts[params.Limit-1], params.Sort)
+	}
+	return page, nil
+}
@@ -0,0 +1,40 @@
+// Package repository : file contains operations with password reset tokens in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// CreatePasswordReset : insert new password reset token
+func (r *PRepository) CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error {
+	_, err := r.PPool.Exec(ctx, `insert into password_resets(id,user_id,token_hash,created_at,expires_at)
+		values($1,$2,$3,$4,$5)`,
+		reset.ID, reset.UserID, reset.TokenHash, reset.CreatedAt, reset.ExpiresAt)
+	if err != nil {
+		log.Errorf("database error with create password reset: %v", err)
+		return pgError(err, "password reset")
+	}
+	return nil
+}
+
+// UsePasswordReset : mark not used and not expired password reset token as used and return it
+func (r *PRepository) UsePasswordReset(ctx context.Context, tokenHash string) (model.PasswordReset, error) {
+	p := model.PasswordReset{}
+	err := r.PPool.QueryRow(ctx, `update password_resets set used_at=now()
+		where token_hash=$1 and used_at is null and expires_at>now()
+		returning id,user_id,token_hash,created_at,expires_at,used_at`, tokenHash).Scan(
+		&p.ID, &p.UserID, &p.TokenHash, &p.CreatedAt, &p.ExpiresAt, &p.UsedAt)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, use password reset: %v", err)
+			return model.PasswordReset{}, pgError(err, "password reset")
+		}
+		return model.PasswordReset{}, fmt.Errorf("password reset token is invalid or expired: %w", model.ErrNotFound)
+	}
+	return p, nil
+}
@@ -0,0 +1,46 @@
+// Package repository : file contains full-text search of adverts in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/search"
+	"context"
+	"strings"
+
+	"github.com/labstack/gommon/log"
+)
+
+// SearchAdverts find published adverts with any word of query, words of query match as prefixes,
+// ranked by ts_rank with title weighted above address and category, and those above description
+func (r *PRepository) SearchAdverts(ctx context.Context, query string, limit int) ([]model.AdvertHit, error) {
+	terms := search.Terms(query)
+	if len(terms) == 0 {
+		return []model.AdvertHit{}, nil
+	}
+	tsQuery := strings.Join(terms, ":* | ") + ":*" // terms contain only letters and digits
+	rows, err := r.PPool.Query(ctx, `select `+advertColumns+`,ts_rank(search,q) rank
+		from adverts, to_tsquery('simple',$1) q where search @@ q and status=$2 order by rank desc, id limit $3`,
+		tsQuery, model.AdvertPublished, limit)
+	if err != nil {
+		log.Errorf("database error with search adverts, %v", err)
+		return nil, pgError(err, "advert")
+	}
+	defer rows.Close()
+	hits := make([]model.AdvertHit, 0)
+	for rows.Next() {
+		hit := model.AdvertHit{}
+		var rank float32
+		hit.Advert, err = scanAdvert(rows, &rank)
+		if err != nil {
+			log.Errorf("database error with search adverts, %v", err)
+			return nil, pgError(err, "advert")
+		}
+		hit.Rank = float64(rank)
+		hit.Highlights = search.HighlightAdvert(hit.Advert, terms)
+		hits = append(hits, hit)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, pgError(err, "advert")
+	}
+	return hits, nil
+}
@@ -0,0 +1,107 @@
+// Package repository : file contains operations with refresh tokens in PostgresDB
+package repository
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/labstack/gommon/log"
+)
+
+// CreateRefreshToken : insert new refresh token
+func (r *PRepository) CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error {
+	_, err := r.PPool.Exec(ctx, `insert into refresh_tokens(id,family_id,user_id,token_hash,user_agent,ip,created_at,expires_at)
+		values($1,$2,$3,$4,$5,$6,$7,$8)`,
+		token.ID, token.FamilyID, token.UserID, token.TokenHash, token.UserAgent, token.IP, token.CreatedAt, token.ExpiresAt)
+	if err != nil {
+		log.Errorf("database error with create refresh token: %v", err)
+		return pgError(err, "refresh token")
+	}
+	return nil
+}
+
+// SelectRefreshToken : select refresh token by hash of its value
+func (r *PRepository) SelectRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error) {
+	t := model.RefreshToken{}
+	err := r.PPool.QueryRow(ctx, `select id,family_id,user_id,token_hash,user_agent,ip,created_at,expires_at,used_at,revoked_at
+		from refresh_tokens where token_hash=$1`, tokenHash).Scan(
+		&t.ID, &t.FamilyID, &t.UserID, &t.TokenHash, &t.UserAgent, &t.IP, &t.CreatedAt, &t.ExpiresAt, &t.UsedAt, &t.RevokedAt)
+	if err != nil {
+		if err != pgx.ErrNoRows {
+			log.Errorf("database error, select refresh token: %v", err)
+		}
+		return model.RefreshToken{}, pgError(err, "refresh token")
+	}
+	return t, nil
+}
+
+// UseRefreshToken : mark refresh token as used, returns false if it was already used before
+func (r *PRepository) UseRefreshToken(ctx context.Context, id string) (bool, error) {
+	a, err := r.PPool.Exec(ctx, "update refresh_tokens set used_at=now() where id=$1 and used_at is null", id)
+	if err != nil {
+		log.Errorf("error with use refresh token %v", err)
+		return false, pgError(err, "refresh token")
+	}
+	return a.RowsAffected() == 1, nil
+}
+
+// RevokeRefreshTokenFamily : revoke all refresh tokens issued for one login
+func (r *PRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	_, err := r.PPool.Exec(ctx, "update refresh_tokens set revoked_at=now() where family_id=$1 and revoked_at is null", familyID)
+	if err != nil {
+		log.Errorf("error with revoke refresh token family %v", err)
+		return pgError(err, "refresh token")
+	}
+	return nil
+}
+
+// RevokeUserRefreshTokens : revoke all refresh tokens of user
+func (r *PRepository) RevokeUserRefreshTokens(ctx context.Context, userID string) error {
+	_, err := r.PPool.Exec(ctx, "update refresh_tokens set revoked_at=now() where user_id=$1 and revoked_at is null", userID)
+	if err != nil {
+		log.Errorf("error with revoke user refresh tokens %v", err)
+		return pgError(err, "refresh token")
+	}
+	return nil
+}
+
+// SelectSessions : select active sessions of user, session is described by its last not used refresh token
+func (r *PRepository) SelectSessions(ctx context.Context, userID string) ([]model.Session, error) {
+	rows, err := r.PPool.Query(ctx, `select t.family_id,f.created_at,t.created_at,t.expires_at,t.user_agent,t.ip
+		from refresh_tokens t
+		join (select family_id, min(created_at) created_at from refresh_tokens where user_id=$1 group by family_id) f
+		on f.family_id=t.family_id
+		where t.user_id=$1 and t.used_at is null and t.revoked_at is null and t.expires_at>now()
+		order by t.created_at desc`, userID)
+	if err != nil {
+		log.Errorf("database error with select sessions, %v", err)
+		return nil, pgError(err, "session")
+	}
+	defer rows.Close()
+	sessions := []model.Session{}
+	for rows.Next() {
+		s := model.Session{}
+		err = rows.Scan(&s.ID, &s.CreatedAt, &s.LastUsedAt, &s.ExpiresAt, &s.UserAgent, &s.IP)
+		if err != nil {
+			log.Errorf("database error with select sessions, %v", err)
+			return nil, pgError(err, "session")
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+// RevokeSession : revoke refresh tokens of one session of user
+func (r *PRepository) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	a, err := r.PPool.Exec(ctx, "update refresh_tokens set revoked_at=now() where user_id=$1 and family_id=$2 and revoked_at is null",
+		userID, sessionID)
+	if err != nil {
+		log.Errorf("error with revoke session %v", err)
+		return pgError(err, "session")
+	}
+	if a.RowsAffected() == 0 {
+		return notFound("session")
+	}
+	return nil
+}
@@ -3,7 +3,9 @@ package repository
 
 import (
 	"awesomeProject/internal/model"
+	"awesomeProject/internal/search"
 	"context"
+	"time"
 )
 
 // Repository middleware
@@ -11,18 +13,45 @@ type Repository interface {
 	Create(ctx context.Context, person *model.Person) (string, error)
 	CreateAdvert(ctx context.Context, advert *model.Advert) (string, error)
 
-	UpdateAuth(ctx context.Context, id string, refreshToken string) error
 	Update(ctx context.Context, id string, person *model.Person) error
 	UpdateAdvert(ctx context.Context, id string, advert *model.Advert) error
+	UpdateAdvertStatus(ctx context.Context, id, from, to string, updatedAt time.Time) error
+	CreateImage(ctx context.Context, image *model.Image, limit int) error
+	SelectImages(ctx context.Context, advertID string) ([]model.Image, error)
+	SelectImage(ctx context.Context, advertID, id string) (model.Image, error)
+	UpdateRoles(ctx context.Context, id string, roles []string) error
+	UpdatePassword(ctx context.Context, id, password string) error
 
-	SelectAll(ctx context.Context) ([]*model.Person, error)
-	SelectAllAdvert(ctx context.Context) ([]*model.Advert, error)
+	SelectAdvertsByOwner(ctx context.Context, ownerID, status string) ([]*model.Advert, error)
+	SelectUsersPage(ctx context.Context, params model.ListParams) (model.UserPage, error)
+	SelectAdvertsPage(ctx context.Context, params model.ListParams, filter model.AdvertFilter) (model.AdvertPage, error)
+	search.AdvertSearcher
+	SelectNearbyAdverts(ctx context.Context, params model.NearbyParams) ([]model.AdvertDistance, error)
 
 	SelectByID(ctx context.Context, id string) (model.Person, error)
 	SelectAdvertByID(ctx context.Context, id string) (model.Advert, error)
 
-	SelectByIDAuth(ctx context.Context, id string) (model.Person, error)
+	SelectByName(ctx context.Context, name string) (model.Person, error)
 
 	Delete(ctx context.Context, id string) error
 	DeleteAdvert(ctx context.Context, id string) error
+
+	CreateRefreshToken(ctx context.Context, token *model.RefreshToken) error
+	SelectRefreshToken(ctx context.Context, tokenHash string) (model.RefreshToken, error)
+	UseRefreshToken(ctx context.Context, id string) (bool, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID string) error
+	SelectSessions(ctx context.Context, userID string) ([]model.Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID string) error
+
+	CreatePasswordReset(ctx context.Context, reset *model.PasswordReset) error
+	UsePasswordReset(ctx context.Context, tokenHash string) (model.PasswordReset, error)
+}
+
+// creationTime time when entity is created, now if caller didnt set it
+func creationTime(t time.Time) time.Time {
+	if t.IsZero() {
+		return time.Now()
+	}
+	return t
 }
@@ -0,0 +1,163 @@
+// Package search : file contains words of search queries, highlighting of found adverts and in-process advert index
+package search
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// highlight marks around matched words
+const (
+	MarkStart = "<mark>"
+	MarkStop  = "</mark>"
+)
+
+// AdvertSearcher search published adverts by any word of query, words of query match as prefixes, best matches
+// first, query without words finds nothing. Repositories implement it in their databases
+type AdvertSearcher interface {
+	SearchAdverts(ctx context.Context, query string, limit int) ([]model.AdvertHit, error)
+}
+
+// Terms split text to lower case words
+func Terms(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Highlight wrap words of text which start with one of terms into marks, text is escaped so result is safe html
+func Highlight(text string, terms []string) string {
+	var b strings.Builder
+	word := -1
+	flush := func(end int) {
+		if word < 0 {
+			return
+		}
+		if matches(strings.ToLower(text[word:end]), terms) {
+			b.WriteString(MarkStart + html.EscapeString(text[word:end]) + MarkStop)
+		} else {
+			b.WriteString(html.EscapeString(text[word:end]))
+		}
+		word = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if word < 0 {
+				word = i
+			}
+			continue
+		}
+		flush(i)
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	flush(len(text))
+	return b.String()
+}
+
+// fields searched text of advert by json name
+func fields(a model.Advert) map[string]string {
+	return map[string]string{"title": a.Title, "description": a.Description, "category": a.Category, "address": a.Address}
+}
+
+// HighlightAdvert highlight searched fields of advert which have words matched by terms
+func HighlightAdvert(a model.Advert, terms []string) map[string]string {
+	highlights := make(map[string]string)
+	for name, text := range fields(a) {
+		for _, word := range Terms(text) {
+			if matches(word, terms) {
+				highlights[name] = Highlight(text, terms)
+				break
+			}
+		}
+	}
+	return highlights
+}
+
+// matches check if word starts with one of terms, so "flats" is found by "flat"
+func matches(word string, terms []string) bool {
+	for _, term := range terms {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// rank share of words of advert matched by terms, zero if advert doesnt match
+func rank(a model.Advert, terms []string) float64 {
+	var words []string
+	for _, text := range fields(a) {
+		words = append(words, Terms(text)...)
+	}
+	matched := 0
+	for _, word := range words {
+		if matches(word, terms) {
+			matched++
+		}
+	}
+	if matched == 0 {
+		return 0
+	}
+	return float64(matched) / float64(len(words))
+}
+
+// Memory in-process advert index, usable in tests without a database
+type Memory struct {
+	mu      sync.RWMutex
+	adverts map[string]model.Advert
+}
+
+// NewMemory create empty in-process index
+func NewMemory() *Memory {
+	return &Memory{adverts: make(map[string]model.Advert)}
+}
+
+// Index add advert to index or replace it
+func (m *Memory) Index(advert model.Advert) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.adverts[advert.ID] = advert
+}
+
+// Remove drop advert from index
+func (m *Memory) Remove(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.adverts, id)
+}
+
+// SearchAdverts rank published adverts by share of their words matched by query
+func (m *Memory) SearchAdverts(_ context.Context, query string, limit int) ([]model.AdvertHit, error) {
+	terms := Terms(query)
+	hits := make([]model.AdvertHit, 0)
+	if len(terms) == 0 {
+		return hits, nil
+	}
+	m.mu.RLock()
+	for _, advert := range m.adverts {
+		if advert.Status != model.AdvertPublished {
+			continue
+		}
+		r := rank(advert, terms)
+		if r == 0 {
+			continue
+		}
+		hits = append(hits, model.AdvertHit{Advert: advert, Rank: r, Highlights: HighlightAdvert(advert, terms)})
+	}
+	m.mu.RUnlock()
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Rank != hits[j].Rank {
+			return hits[i].Rank > hits[j].Rank
+		}
+		return hits[i].Advert.ID < hits[j].Advert.ID
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,60 @@
+package search
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTerms(t *testing.T) {
+	require.Equal(t, []string{"minsk", "lenina", "12"}, Terms("Minsk, Lenina-12!"))
+	require.Empty(t, Terms(" ,.- "))
+}
+
+func TestHighlight(t *testing.T) {
+	require.Equal(t, "<mark>Minsk</mark>, Lenina <mark>12</mark>", Highlight("Minsk, Lenina 12", []string{"minsk", "12"}))
+	require.Equal(t, "big <mark>flats</mark>", Highlight("big flats", []string{"flat"}))
+	require.Equal(t, "Brest", Highlight("Brest", []string{"minsk"}))
+	require.Equal(t, "&lt;<mark>script</mark>&gt;alert(&#34;x&#34;)&lt;/<mark>script</mark>&gt; &amp; flat",
+		Highlight(`<script>alert("x")</script> & flat`, []string{"script"}))
+	require.Equal(t, "&lt;b&gt;Brest&lt;/b&gt;", Highlight("<b>Brest</b>", []string{"minsk"}))
+}
+
+func TestHighlightAdvert(t *testing.T) {
+	advert := model.Advert{Title: "<script>document.location='//evil'</script> flat", Address: "Minsk", Category: "flats"}
+	require.Equal(t, map[string]string{
+		"title":    "&lt;script&gt;document.location=&#39;//evil&#39;&lt;/script&gt; <mark>flat</mark>",
+		"category": "<mark>flats</mark>",
+	}, HighlightAdvert(advert, []string{"flat"}))
+}
+
+func TestMemory_SearchAdverts(t *testing.T) {
+	index := NewMemory()
+	index.Index(model.Advert{ID: "1", Title: "Flat", Address: "Minsk, Lenina 12", Status: model.AdvertPublished})
+	index.Index(model.Advert{ID: "2", Title: "Minsk flat", Address: "Center", Status: model.AdvertPublished})
+	index.Index(model.Advert{ID: "3", Title: "House", Address: "Brest, Sovetskaya 3", Status: model.AdvertPublished})
+	index.Index(model.Advert{ID: "4", Title: "House", Address: "Grodno", Status: model.AdvertPublished})
+	index.Index(model.Advert{ID: "5", Title: "Minsk", Address: "Minsk", Status: model.AdvertDraft})
+	index.Remove("4")
+
+	hits, err := index.SearchAdverts(context.Background(), "minsk", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 2)
+	require.Equal(t, "2", hits[0].Advert.ID, "advert with less other words must rank higher")
+	require.Equal(t, map[string]string{"title": "<mark>Minsk</mark> flat"}, hits[0].Highlights)
+	require.Greater(t, hits[0].Rank, hits[1].Rank)
+	require.Equal(t, map[string]string{"address": "<mark>Minsk</mark>, Lenina 12"}, hits[1].Highlights)
+
+	hits, err = index.SearchAdverts(context.Background(), "brest grodno", 1)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, "3", hits[0].Advert.ID)
+
+	for _, query := range []string{"gomel", " ,.- "} {
+		hits, err = index.SearchAdverts(context.Background(), query, 10)
+		require.NoError(t, err)
+		require.Empty(t, hits, "found by %q", query)
+	}
+}
@@ -3,146 +3,305 @@ package service
 
 import (
 	"awesomeProject/internal/cache"
+	"awesomeProject/internal/jwtkeys"
 	"awesomeProject/internal/model"
+	"awesomeProject/internal/notifier"
+	"awesomeProject/internal/password"
 	"awesomeProject/internal/repository"
+	"awesomeProject/internal/search"
+	"awesomeProject/internal/storage"
 	"context"
 	"fmt"
+	"sync"
+	"time"
 )
 
-// JwtKey fo generation and check tokens
-var JwtKey = []byte("super-key")
+// ErrNotAdvertOwner returned when user tries to modify advert created by someone else
+var ErrNotAdvertOwner = fmt.Errorf("only owner can modify this advert: %w", model.ErrForbidden)
 
 // Service struct
 type Service struct {
-	rps       repository.Repository
-	userCache *cache.UserCache
+	rps         repository.Repository
+	userCache   *cache.UserCache
+	jwtCfg      model.JWTConfig
+	keys        *jwtkeys.KeySet
+	denylist    cache.Denylist
+	passwordCfg model.PasswordConfig
+	policy      *password.Policy
+	notifier    notifier.Notifier
+	loginCfg    model.LoginConfig
+	attempts    cache.LoginAttempts
+	searcher    search.AdvertSearcher
+	storage     storage.Storage
+	imageCfg    model.ImageConfig
+	imageSlots  chan struct{} // decoded images take a lot of memory, so only few are processed at once
+
+	dummyHashOnce sync.Once // hash for logins of unknown users is made once with configured cost
+	dummyHash     []byte
+}
+
+// Deps what service is built from
+type Deps struct {
+	Repository repository.Repository
+	UserCache  *cache.UserCache
+	JWT        model.JWTConfig
+	Keys       *jwtkeys.KeySet
+	Denylist   cache.Denylist
+	Passwords  model.PasswordConfig
+	Policy     *password.Policy
+	Notifier   notifier.Notifier
+	Login      model.LoginConfig
+	Attempts   cache.LoginAttempts
+	Searcher   search.AdvertSearcher // repository is used if nil
+	Storage    storage.Storage
+	Images     model.ImageConfig
 }
 
 // NewService create new service connection
-func NewService(newRps repository.Repository, userCache *cache.UserCache) *Service { // create
-	return &Service{newRps, userCache}
+func NewService(deps Deps) *Service { // create
+	var searcher search.AdvertSearcher = deps.Repository
+	if deps.Searcher != nil {
+		searcher = deps.Searcher
+	}
+	return &Service{rps: deps.Repository, userCache: deps.UserCache, jwtCfg: deps.JWT, keys: deps.Keys, denylist: deps.Denylist,
+		passwordCfg: deps.Passwords, policy: deps.Policy, notifier: deps.Notifier, loginCfg: deps.Login, attempts: deps.Attempts,
+		searcher: searcher, storage: deps.Storage, imageCfg: deps.Images, imageSlots: make(chan struct{}, imageSlots(deps.Images))}
+}
+
+// imageSlots count of images processed at once, at least one
+func imageSlots(cfg model.ImageConfig) int {
+	if cfg.MaxConcurrent < 1 {
+		return 1
+	}
+	return cfg.MaxConcurrent
+}
+
+// CreateAdvert create draft advert in DB and warm cache with it
+func (s *Service) CreateAdvert(ctx context.Context, advert *model.Advert) (model.Advert, error) {
+	advert.CreatedAt = time.Now().UTC().Truncate(time.Microsecond) // precision of postgres
+	advert.UpdatedAt = advert.CreatedAt
+	advert.Status = model.AdvertDraft
+	newID, err := s.rps.CreateAdvert(ctx, advert)
+	if err != nil {
+		return model.Advert{}, fmt.Errorf("failed to create advert, %w", err)
+	}
+	created := *advert
+	created.ID = newID
+	err = s.userCache.AddAdvertToCache(ctx, &created)
+	if err != nil {
+		return model.Advert{}, fmt.Errorf("failed to add advert into the cache, %w", err)
+	}
+	return created, nil
 }
 
-// UpdateUser update user in cache and DB
+// UpdateUser update user in DB and drop his stale cache entry
 func (s *Service) UpdateUser(ctx context.Context, id string, person *model.Person) error { // update user
 	err := s.rps.Update(ctx, id, person)
 	if err != nil {
-		return fmt.Errorf("failed to update users, %e", err)
+		return fmt.Errorf("failed to update users, %w", err)
 	}
-	return s.userCache.AddToCache(ctx, person)
+	return s.userCache.DeleteUserFromCache(ctx, id)
 }
 
-func (s *Service) UpdateAdvert(ctx context.Context, id string, advert *model.Advert) error { // update user
-	err := s.rps.UpdateAdvert(ctx, id, advert)
+// UpdateRoles replace roles of user, his sessions are closed so new roles get into tokens on next login
+func (s *Service) UpdateRoles(ctx context.Context, id string, roles []string) error {
+	err := s.rps.UpdateRoles(ctx, id, roles)
+	if err != nil {
+		return fmt.Errorf("failed to update user roles, %w", err)
+	}
+	err = s.userCache.DeleteUserFromCache(ctx, id)
 	if err != nil {
-		return fmt.Errorf("failed to update users, %e", err)
+		return fmt.Errorf("service: error while deleting user from cache, %w", err)
 	}
-	return s.userCache.AddAdvertToCache(ctx, advert)
+	err = s.revokeAllSessions(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: error while revoking user sessions, %w", err)
+	}
+	return nil
 }
 
-// SelectAllUsers get all users from DB or cache
-func (s *Service) SelectAllUsers(ctx context.Context) ([]*model.Person, error) { // get all users from DB without passwords and tokens
-	users, found, err := s.userCache.GetAllUsersFromCache(ctx)
+// UpdateAdvert update advert of user in DB and drop its stale cache entry
+func (s *Service) UpdateAdvert(ctx context.Context, actor model.Principal, id string, advert *model.Advert) error { // update advert
+	_, err := s.checkAdvertOwner(ctx, actor, id)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select all users from db, %e", err)
+		return err
 	}
-	if !found {
-		users, err = s.rps.SelectAll(ctx)
-		if err != nil {
-			return nil, fmt.Errorf("failed to select all users from db, %e", err)
-		}
-		err = s.userCache.AddAllUsersToCache(ctx, users)
-		if err != nil {
-			return nil, fmt.Errorf("failed to add users into the cache, %e", err)
-		}
-		return users, nil
+	advert.UpdatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	err = s.rps.UpdateAdvert(ctx, id, advert)
+	if err != nil {
+		return fmt.Errorf("failed to update advert, %w", err)
 	}
-	return users, nil
+	return s.userCache.DeleteAdvertFromCache(ctx, id)
 }
 
-func (s *Service) SelectAllAdverts(ctx context.Context) ([]*model.Advert, error) { // get all users from DB without passwords and tokens
-	adverts, found, err := s.userCache.GetAllAdvertsFromCache(ctx)
+// ListUsers get page of users from DB
+func (s *Service) ListUsers(ctx context.Context, params model.ListParams) (model.UserPage, error) {
+	page, err := s.rps.SelectUsersPage(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select all users from db, %e", err)
+		return model.UserPage{}, fmt.Errorf("failed to select users from db, %w", err)
 	}
-	if !found {
-		adverts, err = s.rps.SelectAllAdvert(ctx)
+	return page, nil
+}
+
+// ListAdverts get page of adverts matched by filter from DB
+func (s *Service) ListAdverts(ctx context.Context, params model.ListParams, filter model.AdvertFilter) (model.AdvertPage, error) {
+	page, err := s.rps.SelectAdvertsPage(ctx, params, filter)
+	if err != nil {
+		return model.AdvertPage{}, fmt.Errorf("failed to select adverts from db, %w", err)
+	}
+	return page, nil
+}
+
+// SearchAdverts find at most limit adverts by words of query, best matches first
+func (s *Service) SearchAdverts(ctx context.Context, query string, limit int) ([]model.AdvertHit, error) {
+	if len(search.Terms(query)) == 0 {
+		return nil, &model.ValidationError{Fields: []model.FieldError{{Field: "q", Message: "must contain a word"}}}
+	}
+	hits, err := s.searcher.SearchAdverts(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search adverts, %w", err)
+	}
+	return hits, nil
+}
+
+// NearbyAdverts find published adverts around point, nearest first
+func (s *Service) NearbyAdverts(ctx context.Context, params model.NearbyParams) ([]model.AdvertDistance, error) {
+	found, err := s.rps.SelectNearbyAdverts(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select nearby adverts from db, %w", err)
+	}
+	return found, nil
+}
+
+// DeleteUser delete user by id from cache and DB, his adverts are deleted with him so they are dropped from cache
+// and files of their images are deleted
+func (s *Service) DeleteUser(ctx context.Context, id string) error { // delete user from DB
+	adverts, err := s.rps.SelectAdvertsByOwner(ctx, id, "")
+	if err != nil {
+		return fmt.Errorf("service: error while selecting user adverts, %w", err)
+	}
+	var attached []model.Image
+	for _, advert := range adverts {
+		var images []model.Image
+		images, err = s.rps.SelectImages(ctx, advert.ID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to select all users from db, %e", err)
+			return fmt.Errorf("service: error while selecting advert images, %w", err)
 		}
-		err = s.userCache.AddAllAdvertsToCache(ctx, adverts)
+		attached = append(attached, images...)
+		err = s.userCache.DeleteAdvertFromCache(ctx, advert.ID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to add users into the cache, %e", err)
+			return fmt.Errorf("service: error while deleting advert from cache, %w", err)
 		}
-		return adverts, nil
 	}
-	return adverts, nil
+	err = s.userCache.DeleteUserFromCache(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: error while deleting user from cache, %w", err)
+	}
+	err = s.revokeAllSessions(ctx, id) // sessions of deleted user must not outlive it
+	if err != nil {
+		return fmt.Errorf("service: error while revoking user sessions, %w", err)
+	}
+	err = s.rps.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+	s.deleteImageFiles(ctx, attached...)
+	return nil
 }
 
-// DeleteUser delete user by id from cache
-func (s *Service) DeleteUser(ctx context.Context, id string) error { // delete user from DB
-	_, found, err := s.userCache.GetUserByIDFromCache(ctx)
+// DeleteAdvert delete advert of user by id from cache and DB
+func (s *Service) DeleteAdvert(ctx context.Context, actor model.Principal, id string) error { // delete advert from DB
+	_, err := s.checkAdvertOwner(ctx, actor, id)
 	if err != nil {
 		return err
 	}
-	if !found {
-		return s.rps.Delete(ctx, id)
+	attached, err := s.rps.SelectImages(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: error while selecting advert images, %w", err)
+	}
+	err = s.userCache.DeleteAdvertFromCache(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: error while deleting advert from cache, %w", err)
 	}
-	err = s.userCache.DeleteUserFromCache(ctx)
+	err = s.rps.DeleteAdvert(ctx, id)
 	if err != nil {
-		return fmt.Errorf("service: error while deleting user from cache, %e", err)
+		return err
 	}
-	return s.rps.Delete(ctx, id)
+	s.deleteImageFiles(ctx, attached...)
+	return nil
 }
 
-func (s *Service) DeleteAdvert(ctx context.Context, id string) error { // delete user from DB
-	_, found, err := s.userCache.GetAdvertByIDFromCache(ctx)
+// checkAdvertOwner check that advert with this id was created by actor and return it, admin can modify any advert
+func (s *Service) checkAdvertOwner(ctx context.Context, actor model.Principal, id string) (model.Advert, error) {
+	advert, err := s.rps.SelectAdvertByID(ctx, id)
 	if err != nil {
-		return err
+		return model.Advert{}, fmt.Errorf("failed to select advert from db, %w", err)
 	}
-	if !found {
-		return s.rps.Delete(ctx, id)
+	if advert.OwnerID != actor.ID && !actor.HasRole(model.RoleAdmin) {
+		return model.Advert{}, ErrNotAdvertOwner
+	}
+	return advert, nil
+}
+
+// SelectAdvertsByOwner get adverts created by user from DB, others than owner and admins get only published ones
+func (s *Service) SelectAdvertsByOwner(ctx context.Context, viewer *model.Principal, ownerID string) ([]*model.Advert, error) {
+	status := model.AdvertPublished
+	if model.SeesDraftsOf(viewer, ownerID) {
+		status = ""
 	}
-	err = s.userCache.DeleteAdvertFromCache(ctx)
+	adverts, err := s.rps.SelectAdvertsByOwner(ctx, ownerID, status)
 	if err != nil {
-		return fmt.Errorf("service: error while deleting user from cache, %e", err)
+		return nil, fmt.Errorf("failed to select user adverts from db, %w", err)
 	}
-	return s.rps.Delete(ctx, id)
+	return adverts, nil
 }
 
-// GetUserByID get user by id from db or cache
+// GetUserByID get user by id from cache or db
 func (s *Service) GetUserByID(ctx context.Context, id string) (model.Person, error) { // get one user by id
-	user, found, err := s.userCache.GetUserByIDFromCache(ctx)
+	user, found, err := s.userCache.GetUserByIDFromCache(ctx, id)
 	if err != nil {
-		return model.Person{}, fmt.Errorf("failed to select user from cache, %e", err)
+		return model.Person{}, fmt.Errorf("failed to select user from cache, %w", err)
 	}
 	if !found {
 		user, err = s.rps.SelectByID(ctx, id)
 		if err != nil {
-			return model.Person{}, fmt.Errorf("failed to select user from cache, %e", err)
+			return model.Person{}, fmt.Errorf("failed to select user from db, %w", err)
 		}
 		err = s.userCache.AddToCache(ctx, &user)
 		if err != nil {
-			return model.Person{}, fmt.Errorf("failed to select user from cache, %e", err)
+			return model.Person{}, fmt.Errorf("failed to add user into the cache, %w", err)
 		}
 		return user, nil
 	}
 	return user, nil
 }
 
-func (s *Service) GetAdvertByID(ctx context.Context, id string) (model.Advert, error) { // get one user by id
-	advert, found, err := s.userCache.GetAdvertByIDFromCache(ctx)
+// GetAdvertByID get advert by id from cache or db, not published advert is found only for its owner and admins
+func (s *Service) GetAdvertByID(ctx context.Context, viewer *model.Principal, id string) (model.Advert, error) { // get one advert by id
+	advert, err := s.advertByID(ctx, id)
+	if err != nil {
+		return model.Advert{}, err
+	}
+	if !advert.VisibleTo(viewer) {
+		return model.Advert{}, fmt.Errorf("advert isnt published: %w", model.ErrNotFound)
+	}
+	return advert, nil
+}
+
+// advertByID get advert in any status by id from cache or db
+func (s *Service) advertByID(ctx context.Context, id string) (model.Advert, error) {
+	advert, found, err := s.userCache.GetAdvertByIDFromCache(ctx, id)
 	if err != nil {
-		return model.Advert{}, fmt.Errorf("failed to select user from cache, %e", err)
+		return model.Advert{}, fmt.Errorf("failed to select advert from cache, %w", err)
 	}
 	if !found {
 		advert, err = s.rps.SelectAdvertByID(ctx, id)
 		if err != nil {
-			return model.Advert{}, fmt.Errorf("failed to select user from cache, %e", err)
+			return model.Advert{}, fmt.Errorf("failed to select advert from db, %w", err)
 		}
 		err = s.userCache.AddAdvertToCache(ctx, &advert)
 		if err != nil {
-			return model.Advert{}, fmt.Errorf("failed to select user from cache, %e", err)
+			return model.Advert{}, fmt.Errorf("failed to add advert into the cache, %w", err)
 		}
 		return advert, nil
 	}
@@ -150,24 +309,11 @@ func (s *Service) GetAdvertByID(ctx context.Context, id string) (model.Advert, e
 }
 
 // DeleteFromCache delete user from cache
-func (s *Service) DeleteFromCache(ctx context.Context) error {
-	_, found, err := s.userCache.GetUserByIDFromCache(ctx)
-	if err != nil {
-		return err
-	}
-	if !found {
-		return nil
-	}
-	return s.userCache.DeleteUserFromCache(ctx)
+func (s *Service) DeleteFromCache(ctx context.Context, id string) error {
+	return s.userCache.DeleteUserFromCache(ctx, id)
 }
 
-func (s *Service) DeleteAdvertFromCache(ctx context.Context) error {
-	_, found, err := s.userCache.GetAdvertByIDFromCache(ctx)
-	if err != nil {
-		return err
-	}
-	if !found {
-		return nil
-	}
-	return s.userCache.DeleteAdvertFromCache(ctx)
+// DeleteAdvertFromCache delete advert from cache
+func (s *Service) DeleteAdvertFromCache(ctx context.Context, id string) error {
+	return s.userCache.DeleteAdvertFromCache(ctx, id)
 }
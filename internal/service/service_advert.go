@@ -0,0 +1,58 @@
+// Package service : file contains lifecycle of adverts
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+	"time"
+)
+
+// advertTransitions statuses advert can be moved to from each status
+var advertTransitions = map[string][]string{
+	model.AdvertDraft:     {model.AdvertPublished, model.AdvertArchived},
+	model.AdvertPublished: {model.AdvertArchived},
+	model.AdvertArchived:  {model.AdvertPublished},
+}
+
+// canMoveAdvert check if advert can be moved from status to another one
+func canMoveAdvert(from, to string) bool {
+	for _, status := range advertTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
+// PublishAdvert make draft or archived advert of actor visible in lists and search
+func (s *Service) PublishAdvert(ctx context.Context, actor model.Principal, id string) (model.Advert, error) {
+	return s.moveAdvert(ctx, actor, id, model.AdvertPublished)
+}
+
+// ArchiveAdvert hide advert of actor from lists and search
+func (s *Service) ArchiveAdvert(ctx context.Context, actor model.Principal, id string) (model.Advert, error) {
+	return s.moveAdvert(ctx, actor, id, model.AdvertArchived)
+}
+
+// moveAdvert change status of advert and drop its stale cache entry
+func (s *Service) moveAdvert(ctx context.Context, actor model.Principal, id, status string) (model.Advert, error) {
+	advert, err := s.checkAdvertOwner(ctx, actor, id)
+	if err != nil {
+		return model.Advert{}, err
+	}
+	if !canMoveAdvert(advert.Status, status) {
+		return model.Advert{}, fmt.Errorf("%s advert cant become %s: %w", advert.Status, status, model.ErrConflict)
+	}
+	updatedAt := time.Now().UTC().Truncate(time.Microsecond)
+	err = s.rps.UpdateAdvertStatus(ctx, id, advert.Status, status, updatedAt)
+	if err != nil {
+		return model.Advert{}, fmt.Errorf("failed to change advert status, %w", err)
+	}
+	err = s.userCache.DeleteAdvertFromCache(ctx, id)
+	if err != nil {
+		return model.Advert{}, fmt.Errorf("failed to delete advert from cache, %w", err)
+	}
+	advert.Status, advert.UpdatedAt = status, updatedAt
+	return advert, nil
+}
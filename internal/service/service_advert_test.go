@@ -0,0 +1,68 @@
+package service
+
+import (
+	"awesomeProject/internal/cache"
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v9"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestService service with test database, redis cache and in-memory image storage
+func newTestService(t *testing.T, images model.ImageConfig) *Service {
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+	deps := testDeps()
+	deps.UserCache = cache.NewCache(client, "test:", time.Minute)
+	deps.Images = images
+	return NewService(deps)
+}
+
+// createTestAdvert register user and create his draft advert
+func createTestAdvert(ctx context.Context, t *testing.T, s *Service) (model.Principal, model.Advert) {
+	ownerID, err := s.Registration(ctx, &model.Person{Name: "owner-" + uuid.New().String(), Password: "tujh2004"})
+	require.NoError(t, err, "cannot register owner")
+	advert, err := s.CreateAdvert(ctx, &model.Advert{Title: "Flat", Category: "flats", Address: "Minsk", Price: 10000,
+		Currency: "BYN", OwnerID: ownerID})
+	require.NoError(t, err, "cannot create advert")
+	return model.Principal{ID: ownerID, Roles: []string{model.RoleUser}}, advert
+}
+
+func TestService_AdvertVisibility(t *testing.T) {
+	s := newTestService(t, model.ImageConfig{})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	owner, draft := createTestAdvert(ctx, t, s)
+	stranger := &model.Principal{ID: uuid.New().String(), Roles: []string{model.RoleUser}}
+	admin := &model.Principal{ID: uuid.New().String(), Roles: []string{model.RoleUser, model.RoleAdmin}}
+	published, err := s.CreateAdvert(ctx, &model.Advert{Title: "House", Category: "houses", Address: "Brest", Price: 20000,
+		Currency: "BYN", OwnerID: owner.ID})
+	require.NoError(t, err, "cannot create advert")
+	_, err = s.PublishAdvert(ctx, owner, published.ID)
+	require.NoError(t, err, "cannot publish advert")
+
+	for _, viewer := range []*model.Principal{nil, stranger} {
+		_, err = s.GetAdvertByID(ctx, viewer, draft.ID)
+		require.True(t, errors.Is(err, model.ErrNotFound), "draft is shown to %+v: %v", viewer, err)
+		_, err = s.GetAdvertByID(ctx, viewer, published.ID)
+		require.NoError(t, err, "published advert is hidden from %+v", viewer)
+		adverts, err := s.SelectAdvertsByOwner(ctx, viewer, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, adverts, 1, "drafts are listed to %+v", viewer)
+		require.Equal(t, published.ID, adverts[0].ID)
+	}
+	for _, viewer := range []*model.Principal{&owner, admin} {
+		_, err = s.GetAdvertByID(ctx, viewer, draft.ID)
+		require.NoError(t, err, "draft is hidden from %+v", viewer)
+		adverts, err := s.SelectAdvertsByOwner(ctx, viewer, owner.ID)
+		require.NoError(t, err)
+		require.Len(t, adverts, 2, "drafts arent listed to %+v", viewer)
+	}
+}
@@ -0,0 +1,131 @@
+// Package service : file contains images of adverts
+package service
+
+import (
+	"awesomeProject/internal/images"
+	"awesomeProject/internal/model"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/log"
+)
+
+// imageKey key of image file in storage, thumbnail is kept next to it
+func imageKey(image model.Image, thumbnail bool) string {
+	key := "adverts/" + image.AdvertID + "/" + image.ID
+	if thumbnail {
+		key += ".thumb"
+	}
+	return key
+}
+
+// AddAdvertImage check uploaded image by its content, store it with thumbnail and attach to advert of actor
+func (s *Service) AddAdvertImage(ctx context.Context, actor model.Principal, advertID string, r io.Reader) (model.Image, error) {
+	_, err := s.checkAdvertOwner(ctx, actor, advertID)
+	if err != nil {
+		return model.Image{}, err
+	}
+	attached, err := s.rps.SelectImages(ctx, advertID)
+	if err != nil {
+		return model.Image{}, fmt.Errorf("failed to select advert images, %w", err)
+	}
+	if len(attached) >= s.imageCfg.MaxPerAdvert { // saves processing, limit itself is kept by CreateImage
+		return model.Image{}, fmt.Errorf("advert already has %d images: %w", len(attached), model.ErrConflict)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, s.imageCfg.MaxSize+1))
+	if err != nil {
+		return model.Image{}, fmt.Errorf("failed to read image, %v: %w", err, model.ErrValidation)
+	}
+	if int64(len(data)) > s.imageCfg.MaxSize {
+		return model.Image{}, fmt.Errorf("image is bigger than %d bytes: %w", s.imageCfg.MaxSize, model.ErrTooLarge)
+	}
+	if len(data) == 0 {
+		return model.Image{}, fmt.Errorf("image is empty: %w", model.ErrValidation)
+	}
+	processed, err := s.processImage(ctx, data)
+	if err != nil {
+		return model.Image{}, err
+	}
+	image := model.Image{
+		ID:          uuid.New().String(),
+		AdvertID:    advertID,
+		ContentType: processed.ContentType,
+		Size:        int64(len(data)),
+		Width:       processed.Width,
+		Height:      processed.Height,
+		CreatedAt:   time.Now().UTC().Truncate(time.Microsecond),
+	}
+	err = s.storage.Put(ctx, imageKey(image, false), bytes.NewReader(data))
+	if err == nil {
+		err = s.storage.Put(ctx, imageKey(image, true), bytes.NewReader(processed.Thumbnail))
+	}
+	if err == nil {
+		err = s.rps.CreateImage(ctx, &image, s.imageCfg.MaxPerAdvert)
+	}
+	if err != nil {
+		s.deleteImageFiles(ctx, image) // files without record are never served
+		return model.Image{}, fmt.Errorf("failed to store image, %w", err)
+	}
+	return image, nil
+}
+
+// processImage check and make thumbnail of image when there is free slot for it
+func (s *Service) processImage(ctx context.Context, data []byte) (images.Processed, error) {
+	select {
+	case s.imageSlots <- struct{}{}:
+	case <-ctx.Done():
+		return images.Processed{}, fmt.Errorf("image processing is canceled, %w", ctx.Err())
+	}
+	defer func() { <-s.imageSlots }()
+	return images.Process(data, s.imageCfg)
+}
+
+// AdvertImages get images attached to advert visible to viewer, oldest first
+func (s *Service) AdvertImages(ctx context.Context, viewer *model.Principal, advertID string) ([]model.Image, error) {
+	_, err := s.GetAdvertByID(ctx, viewer, advertID)
+	if err != nil {
+		return nil, err
+	}
+	attached, err := s.rps.SelectImages(ctx, advertID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select advert images, %w", err)
+	}
+	return attached, nil
+}
+
+// OpenAdvertImage find image of advert visible to viewer and open its file or thumbnail, caller closes it
+func (s *Service) OpenAdvertImage(ctx context.Context, viewer *model.Principal, advertID, id string, thumbnail bool) (model.ImageFile, error) {
+	advert, err := s.GetAdvertByID(ctx, viewer, advertID)
+	if err != nil {
+		return model.ImageFile{}, err
+	}
+	image, err := s.rps.SelectImage(ctx, advertID, id)
+	if err != nil {
+		return model.ImageFile{}, fmt.Errorf("failed to select image, %w", err)
+	}
+	file, err := s.storage.Open(ctx, imageKey(image, thumbnail))
+	if err != nil {
+		return model.ImageFile{}, fmt.Errorf("failed to open image, %w", err)
+	}
+	opened := model.ImageFile{ContentType: image.ContentType, Public: advert.Status == model.AdvertPublished, Body: file}
+	if thumbnail {
+		opened.ContentType = images.ThumbnailContentType
+	}
+	return opened, nil
+}
+
+// deleteImageFiles delete files of images and their thumbnails, failures are only logged as records are already gone
+func (s *Service) deleteImageFiles(ctx context.Context, attached ...model.Image) {
+	for _, image := range attached {
+		for _, thumbnail := range []bool{false, true} {
+			err := s.storage.Delete(ctx, imageKey(image, thumbnail))
+			if err != nil {
+				log.Errorf("failed to delete image file %s, %v", imageKey(image, thumbnail), err)
+			}
+		}
+	}
+}
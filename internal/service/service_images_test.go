@@ -0,0 +1,113 @@
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testImageConfig = model.ImageConfig{MaxSize: 1 << 20, MaxPixels: 1000 * 1000, MaxPerAdvert: 2, ThumbnailSize: 32}
+
+func testImage(t *testing.T) []byte {
+	buf := bytes.Buffer{}
+	require.NoError(t, png.Encode(&buf, image.NewNRGBA(image.Rect(0, 0, 64, 48))))
+	return buf.Bytes()
+}
+
+func TestService_AdvertImageVisibility(t *testing.T) {
+	s := newTestService(t, testImageConfig)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	owner, advert := createTestAdvert(ctx, t, s)
+	img, err := s.AddAdvertImage(ctx, owner, advert.ID, bytes.NewReader(testImage(t)))
+	require.NoError(t, err, "cannot attach image")
+
+	_, err = s.AdvertImages(ctx, nil, advert.ID)
+	require.True(t, errors.Is(err, model.ErrNotFound), "images of draft are listed to anonymous: %v", err)
+	_, err = s.OpenAdvertImage(ctx, nil, advert.ID, img.ID, true)
+	require.True(t, errors.Is(err, model.ErrNotFound), "image of draft is shown to anonymous: %v", err)
+	file, err := s.OpenAdvertImage(ctx, &owner, advert.ID, img.ID, false)
+	require.NoError(t, err, "owner cannot see image of draft")
+	require.NoError(t, file.Body.Close())
+	require.False(t, file.Public, "image of draft can be cached by anyone")
+
+	_, err = s.PublishAdvert(ctx, owner, advert.ID)
+	require.NoError(t, err, "cannot publish advert")
+	file, err = s.OpenAdvertImage(ctx, nil, advert.ID, img.ID, false)
+	require.NoError(t, err, "image of published advert is hidden")
+	require.NoError(t, file.Body.Close())
+	require.True(t, file.Public)
+	require.Equal(t, "image/png", file.ContentType)
+}
+
+func TestService_processImage(t *testing.T) {
+	s := NewService(Deps{Images: testImageConfig})
+	s.imageSlots <- struct{}{} // the only slot is taken by other upload
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := s.processImage(ctx, testImage(t))
+	require.True(t, errors.Is(err, context.DeadlineExceeded), "image is processed without free slot: %v", err)
+	<-s.imageSlots
+	processed, err := s.processImage(context.Background(), testImage(t))
+	require.NoError(t, err, "image isnt processed with free slot")
+	require.Equal(t, 64, processed.Width)
+	require.Empty(t, s.imageSlots, "slot isnt released")
+}
+
+func TestService_AddAdvertImageLimit(t *testing.T) {
+	images := testImageConfig
+	images.MaxConcurrent = 4
+	s := newTestService(t, images)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	owner, advert := createTestAdvert(ctx, t, s)
+
+	const uploads = 6
+	data := testImage(t)
+	errs := make(chan error, uploads)
+	for i := 0; i < uploads; i++ {
+		go func() {
+			_, err := s.AddAdvertImage(ctx, owner, advert.ID, bytes.NewReader(data))
+			errs <- err
+		}()
+	}
+	added := 0
+	for i := 0; i < uploads; i++ {
+		err := <-errs
+		if err == nil {
+			added++
+			continue
+		}
+		require.True(t, errors.Is(err, model.ErrConflict), "unexpected error %v", err)
+	}
+	require.Equal(t, testImageConfig.MaxPerAdvert, added, "concurrent uploads exceed limit")
+	attached, err := s.AdvertImages(ctx, &owner, advert.ID)
+	require.NoError(t, err)
+	require.Len(t, attached, testImageConfig.MaxPerAdvert)
+}
+
+func TestService_DeleteUserImages(t *testing.T) {
+	s := newTestService(t, testImageConfig)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	owner, advert := createTestAdvert(ctx, t, s)
+	img, err := s.AddAdvertImage(ctx, owner, advert.ID, bytes.NewReader(testImage(t)))
+	require.NoError(t, err, "cannot attach image")
+	_, err = s.GetAdvertByID(ctx, &owner, advert.ID) // put advert into cache
+	require.NoError(t, err)
+
+	require.NoError(t, s.DeleteUser(ctx, owner.ID), "cannot delete user")
+	_, err = s.GetAdvertByID(ctx, &owner, advert.ID)
+	require.True(t, errors.Is(err, model.ErrNotFound), "advert of deleted user is served from cache: %v", err)
+	for _, thumbnail := range []bool{false, true} {
+		_, err = s.storage.Open(ctx, imageKey(img, thumbnail))
+		require.True(t, errors.Is(err, model.ErrNotFound), "image file of deleted user is left: %v", err)
+	}
+}
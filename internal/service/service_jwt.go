@@ -2,122 +2,367 @@
 package service
 
 import (
+	"awesomeProject/internal/cache"
+	"awesomeProject/internal/jwtkeys"
 	"awesomeProject/internal/model"
-	"awesomeProject/internal/repository"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 	"github.com/labstack/gommon/log"
 	"golang.org/x/crypto/bcrypt"
 )
 
-var (
-	accessTokenWorkTime  = time.Now().Add(time.Minute * 5).Unix()
-	refreshTokenWorkTime = time.Now().Add(time.Hour * 3).Unix()
+// tokenType scheme which client should use with access token
+const tokenType = "Bearer"
+
+// kinds of issued tokens, stored in "typ" claim so refresh token cant be used as access one
+const (
+	AccessTokenKind  = "access"
+	RefreshTokenKind = "refresh"
 )
 
-// Authentication login in account
-func (s *Service) Authentication(ctx context.Context, id, password string) (accessTokenStr, refreshTokenStr string, err error) {
-	authUser, err := s.rps.SelectByID(ctx, id)
+// ParseToken check token signature by key from its kid header, expiry, issuer, audience and kind and return its claims
+func ParseToken(cfg model.JWTConfig, keys *jwtkeys.KeySet, tokenString, kind string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, keys.Keyfunc)
+	if err != nil {
+		return nil, fmt.Errorf("service: can't parse token, %v: %w", err, model.ErrUnauthorized)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, fmt.Errorf("service: invalid token: %w", model.ErrUnauthorized)
+	}
+	if !claims.VerifyIssuer(cfg.Issuer, true) || !claims.VerifyAudience(cfg.Audience, true) {
+		return nil, fmt.Errorf("service: token issued for another service: %w", model.ErrUnauthorized)
+	}
+	if claims["typ"] != kind {
+		return nil, fmt.Errorf("service: expected %s token: %w", kind, model.ErrUnauthorized)
+	}
+	return claims, nil
+}
+
+// PrincipalFromClaims take user id and roles from access token claims
+func PrincipalFromClaims(claims jwt.MapClaims) (model.Principal, error) {
+	id, ok := claims["sub"].(string)
+	if !ok || id == "" {
+		return model.Principal{}, fmt.Errorf("access token doesnt contain user id: %w", model.ErrUnauthorized)
+	}
+	principal := model.Principal{ID: id}
+	roles, _ := claims["roles"].([]interface{}) // json array after parsing
+	for _, role := range roles {
+		if r, ok := role.(string); ok {
+			principal.Roles = append(principal.Roles, r)
+		}
+	}
+	return principal, nil
+}
+
+// IsTokenRevoked check if access token or its session is in denylist
+func IsTokenRevoked(ctx context.Context, denylist cache.Denylist, claims jwt.MapClaims) (bool, error) {
+	if jti, ok := claims["jti"].(string); ok && jti != "" {
+		revoked, err := denylist.IsRevoked(ctx, tokenDenyKey(jti))
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if sid, ok := claims["sid"].(string); ok && sid != "" {
+		return denylist.IsRevoked(ctx, sessionDenyKey(sid))
+	}
+	return false, nil
+}
+
+// tokenDenyKey denylist key of one access token
+func tokenDenyKey(jti string) string {
+	return "jti:" + jti
+}
+
+// sessionDenyKey denylist key of all access tokens issued for session
+func sessionDenyKey(sid string) string {
+	return "sid:" + sid
+}
+
+// Authentication login in account by unique name and password, starts new session for client
+func (s *Service) Authentication(ctx context.Context, name, password string, client model.ClientInfo) (model.TokenResponse, error) {
+	err := s.checkLoginAllowed(ctx, name, client.IP)
 	if err != nil {
-		return "", "", fmt.Errorf("service: authentication failed - %v", err)
+		return model.TokenResponse{}, err
+	}
+	authUser, err := s.rps.SelectByName(ctx, name)
+	if errors.Is(err, model.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword(s.dummyPasswordHash(), []byte(password)) // unknown name takes as long as wrong password
+		return model.TokenResponse{}, s.loginFailed(ctx, name, client.IP)
+	}
+	if err != nil {
+		return model.TokenResponse{}, fmt.Errorf("service: authentication failed - %w", err)
 	}
 	incoming := []byte(password)
 	existing := []byte(authUser.Password)
 	err = bcrypt.CompareHashAndPassword(existing, incoming) // check passwords
 	if err != nil {
-		return "", "", fmt.Errorf("incorrect password: %v", err)
+		return model.TokenResponse{}, s.loginFailed(ctx, name, client.IP)
+	}
+	err = s.attempts.Reset(ctx, accountAttemptsKey(name))
+	if err != nil {
+		log.Errorf("service: can't reset failed logins of %s - %v", name, err)
 	}
-	authUser.Password = password
+	s.rehashPassword(ctx, &authUser, password)
 
-	return s.CreateJWT(ctx, s.rps, &authUser)
+	return s.CreateJWT(ctx, &authUser, client)
 }
 
-// RefreshToken refresh jwt tokens
-func (s *Service) RefreshToken(ctx context.Context, refreshTokenString string) (accessTokenStr, refreshTokenStr string, err error) { // refresh our tokens
-	refreshToken, err := jwt.Parse(refreshTokenString, func(t *jwt.Token) (interface{}, error) {
-		return JwtKey, nil
-	}) // parse it into string format
+// RefreshToken rotate tokens, replay of already used refresh token revokes the whole session
+func (s *Service) RefreshToken(ctx context.Context, refreshTokenString string, client model.ClientInfo) (model.TokenResponse, error) { // refresh our tokens
+	_, err := ParseToken(s.jwtCfg, s.keys, refreshTokenString, RefreshTokenKind)
 	if err != nil {
-		log.Errorf("service: can't parse refresh token - %e", err)
-		return "", "", err
+		return model.TokenResponse{}, err
 	}
-	if !refreshToken.Valid {
-		return "", "", fmt.Errorf("service: expired refresh token")
+	stored, err := s.rps.SelectRefreshToken(ctx, hashToken(refreshTokenString))
+	if errors.Is(err, model.ErrNotFound) {
+		return model.TokenResponse{}, fmt.Errorf("service: unknown refresh token: %w", model.ErrUnauthorized)
 	}
-	claims := refreshToken.Claims.(jwt.MapClaims)
-	userUUID := claims["jti"]
-	if userUUID == "" {
-		return "", "", fmt.Errorf("service: error while parsing claims, ID couldnt be empty")
+	if err != nil {
+		return model.TokenResponse{}, fmt.Errorf("service: token refresh failed - %w", err)
+	}
+	if stored.RevokedAt != nil {
+		return model.TokenResponse{}, fmt.Errorf("service: refresh token was revoked: %w", model.ErrUnauthorized)
 	}
-	person, err := s.rps.SelectByIDAuth(ctx, userUUID.(string))
+	fresh, err := s.rps.UseRefreshToken(ctx, stored.ID)
 	if err != nil {
-		return "", "", fmt.Errorf("service: token refresh failed - %e", err)
+		return model.TokenResponse{}, fmt.Errorf("service: token refresh failed - %w", err)
 	}
-	if refreshTokenString != person.RefreshToken {
-		return "", "", fmt.Errorf("service: invalid refresh token")
+	if !fresh { // token was stolen or replayed, nobody can continue this session
+		log.Warnf("service: reuse of refresh token %s, revoking session %s", stored.ID, stored.FamilyID)
+		err = s.rps.RevokeRefreshTokenFamily(ctx, stored.FamilyID)
+		if err == nil {
+			err = s.denylist.Revoke(ctx, sessionDenyKey(stored.FamilyID), s.jwtCfg.AccessTTL)
+		}
+		if err != nil {
+			return model.TokenResponse{}, fmt.Errorf("service: token refresh failed - %w", err)
+		}
+		return model.TokenResponse{}, fmt.Errorf("service: refresh token was already used: %w", model.ErrUnauthorized)
 	}
-	return s.CreateJWT(ctx, s.rps, &person)
+	person, err := s.rps.SelectByID(ctx, stored.UserID)
+	if err != nil {
+		return model.TokenResponse{}, fmt.Errorf("service: token refresh failed - %w", err)
+	}
+	return s.issueTokens(ctx, &person, stored.FamilyID, client)
+}
+
+// CreateJWT create jwt tokens for new session of user
+func (s *Service) CreateJWT(ctx context.Context, person *model.Person, client model.ClientInfo) (model.TokenResponse, error) {
+	return s.issueTokens(ctx, person, uuid.New().String(), client)
 }
 
-// CreateJWT create jwt tokens
-func (s *Service) CreateJWT(ctx context.Context, rps repository.Repository, person *model.Person) (accessTokenStr, refreshTokenStr string, err error) {
-	accessToken := jwt.New(jwt.SigningMethodHS256)         // encrypt access token by SigningMethodHS256 method
-	claimsA := accessToken.Claims.(jwt.MapClaims)          // fill access-token`s claims
-	claimsA["exp"] = accessTokenWorkTime                   // work time
-	claimsA["username"] = person.Name                      // payload
-	accessTokenStr, err = accessToken.SignedString(JwtKey) // convert token to string format
+// issueTokens create access token and refresh token of session familyID and store refresh token hash
+func (s *Service) issueTokens(ctx context.Context, person *model.Person, familyID string, client model.ClientInfo) (model.TokenResponse, error) {
+	now := time.Now()
+	accessToken := jwt.MapClaims{
+		"exp":      now.Add(s.jwtCfg.AccessTTL).Unix(), // work time
+		"iat":      now.Unix(),                         // issued at
+		"iss":      s.jwtCfg.Issuer,                    // who issued token
+		"aud":      s.jwtCfg.Audience,                  // who can accept token
+		"username": person.Name,                        // payload
+		"sub":      person.ID,                          // token owner
+		"sid":      familyID,                           // session of token
+		"roles":    person.Roles,                       // what user is allowed to do
+		"jti":      uuid.New().String(),                // token id for revocation
+		"typ":      AccessTokenKind,                    // token kind
+	}
+	accessTokenStr, err := s.keys.Sign(accessToken, now) // sign by current key and convert token to string format
 	if err != nil {
 		log.Errorf("service: can't generate access token - %v", err)
-		return "", "", err
+		return model.TokenResponse{}, err
+	}
+	stored := model.RefreshToken{
+		ID:        uuid.New().String(),
+		FamilyID:  familyID,
+		UserID:    person.ID,
+		UserAgent: client.UserAgent,
+		IP:        client.IP,
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.jwtCfg.RefreshTTL),
 	}
-	refreshToken := jwt.New(jwt.SigningMethodHS256)
-	claimsR := refreshToken.Claims.(jwt.MapClaims)
-	claimsR["username"] = person.Name
-	claimsR["exp"] = refreshTokenWorkTime
-	claimsR["jti"] = person.ID
-	refreshTokenStr, err = refreshToken.SignedString(JwtKey)
+	refreshToken := jwt.MapClaims{
+		"exp": stored.ExpiresAt.Unix(),
+		"iat": now.Unix(),
+		"iss": s.jwtCfg.Issuer,
+		"aud": s.jwtCfg.Audience,
+		"sub": person.ID,
+		"sid": familyID,
+		"jti": stored.ID,
+		"typ": RefreshTokenKind,
+	}
+	refreshTokenStr, err := s.keys.Sign(refreshToken, now)
 	if err != nil {
-		log.Errorf("service: can't generate access token - %v", err)
-		return "", "", err
+		log.Errorf("service: can't generate refresh token - %v", err)
+		return model.TokenResponse{}, err
 	}
-	err = rps.UpdateAuth(ctx, person.ID, refreshTokenStr) // add into user refresh token
+	stored.TokenHash = hashToken(refreshTokenStr) // only hash is stored, leaked db doesnt leak sessions
+	err = s.rps.CreateRefreshToken(ctx, &stored)
 	if err != nil {
-		log.Errorf("service: can't generate access token - %v", err)
-		return "", "", err
+		log.Errorf("service: can't store refresh token - %v", err)
+		return model.TokenResponse{}, err
+	}
+	return model.TokenResponse{
+		UserID:       person.ID,
+		TokenType:    tokenType,
+		AccessToken:  accessTokenStr,
+		RefreshToken: refreshTokenStr,
+		ExpiresIn:    int64(s.jwtCfg.AccessTTL.Seconds()),
+	}, nil
+}
+
+// hashToken hash of token which is kept in db instead of token
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// JWKS public keys for checking issued tokens
+func (s *Service) JWKS() model.JWKS {
+	return s.keys.JWKS()
+}
+
+// Logout revoke access token and close its session, all sessions are closed when token has no session
+func (s *Service) Logout(ctx context.Context, id string, token model.AccessTokenInfo) error {
+	if token.ID != "" {
+		err := s.denylist.Revoke(ctx, tokenDenyKey(token.ID), time.Until(token.ExpiresAt)) // keep it only while token is alive
+		if err != nil {
+			return fmt.Errorf("service: can't revoke access token - %w", err)
+		}
+	}
+	if token.SessionID == "" {
+		return s.LogoutEverywhere(ctx, id)
+	}
+	err := s.RevokeSession(ctx, id, token.SessionID)
+	if err != nil && !errors.Is(err, model.ErrNotFound) { // session already closed
+		return fmt.Errorf("service: logout failed - %w", err)
+	}
+	return nil
+}
+
+// LogoutEverywhere close all sessions of user
+func (s *Service) LogoutEverywhere(ctx context.Context, id string) error {
+	err := s.revokeAllSessions(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: logout failed - %w", err)
+	}
+	return nil
+}
+
+// revokeAllSessions put active sessions of user to denylist and revoke all his refresh tokens
+func (s *Service) revokeAllSessions(ctx context.Context, id string) error {
+	sessions, err := s.rps.SelectSessions(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, session := range sessions {
+		err = s.denylist.Revoke(ctx, sessionDenyKey(session.ID), s.jwtCfg.AccessTTL)
+		if err != nil {
+			return err
+		}
+	}
+	return s.rps.RevokeUserRefreshTokens(ctx, id)
+}
+
+// Sessions get active sessions of user, currentSID is marked as current
+func (s *Service) Sessions(ctx context.Context, id, currentSID string) ([]model.Session, error) {
+	sessions, err := s.rps.SelectSessions(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("service: can't get sessions - %w", err)
 	}
-	return
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSID
+	}
+	return sessions, nil
 }
 
-// UpdateUserAuth update auth user, add token
-func (s *Service) UpdateUserAuth(ctx context.Context, id, refreshToken string) error {
-	return s.rps.UpdateAuth(ctx, id, refreshToken)
+// RevokeSession close one session of user
+func (s *Service) RevokeSession(ctx context.Context, id, sid string) error {
+	err := s.rps.RevokeSession(ctx, id, sid)
+	if err != nil {
+		return fmt.Errorf("service: can't revoke session - %w", err)
+	}
+	err = s.denylist.Revoke(ctx, sessionDenyKey(sid), s.jwtCfg.AccessTTL) // access tokens of session live at most AccessTTL
+	if err != nil {
+		return fmt.Errorf("service: can't revoke session - %w", err)
+	}
+	return nil
 }
 
 // Registration create new account
 func (s *Service) Registration(ctx context.Context, person *model.Person) (string, error) { // users`s registration
-	hPassword, err := HashPassword(person.Password)
+	if strings.TrimSpace(person.Name) == "" {
+		return "", &model.ValidationError{Fields: []model.FieldError{{Field: "name", Message: "is required"}}}
+	}
+	err := s.policy.Check("password", person.Password)
 	if err != nil {
-		return " ", err
+		return "", err
 	}
-	person.Password = hPassword
-	newID, err := s.rps.Create(ctx, person)
+	hPassword, err := HashPasswordWithCost(person.Password, s.passwordCfg.BcryptCost)
 	if err != nil {
 		return "", err
 	}
-
-	return newID, nil
+	person.Password = hPassword
+	person.Roles = []string{model.RoleUser} // roles are granted only by admin
+	person.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	return s.rps.Create(ctx, person)
 }
 
-// HashPassword hash password
+// HashPassword hash password with default cost
 func HashPassword(password string) (string, error) {
+	return HashPasswordWithCost(password, bcrypt.DefaultCost)
+}
+
+// HashPasswordWithCost hash password, blank passwords are not allowed
+func HashPasswordWithCost(password string, cost int) (string, error) {
+	if strings.TrimSpace(password) == "" {
+		return "", fmt.Errorf("service: password is empty: %w", model.ErrValidation)
+	}
 	bytesPassword := []byte(password)
-	hashedBytesPassword, err := bcrypt.GenerateFromPassword(bytesPassword, bcrypt.DefaultCost)
+	hashedBytesPassword, err := bcrypt.GenerateFromPassword(bytesPassword, cost)
 	if err != nil {
 		return "", err
 	}
 	hashPassword := string(hashedBytesPassword)
 	return hashPassword, nil
 }
+
+// dummyPasswordHash hash of random password with configured cost, compared on login of unknown user
+func (s *Service) dummyPasswordHash() []byte {
+	s.dummyHashOnce.Do(func() {
+		hash, err := bcrypt.GenerateFromPassword([]byte(uuid.NewString()), s.passwordCfg.BcryptCost)
+		if err != nil {
+			log.Errorf("service: can't generate dummy password hash - %v", err)
+			return
+		}
+		s.dummyHash = hash
+	})
+	return s.dummyHash
+}
+
+// rehashPassword store new hash of password when stored one is weaker than configured cost, login doesnt fail because of it
+func (s *Service) rehashPassword(ctx context.Context, person *model.Person, password string) {
+	cost, err := bcrypt.Cost([]byte(person.Password))
+	if err != nil || cost >= s.passwordCfg.BcryptCost {
+		return
+	}
+	hash, err := HashPasswordWithCost(password, s.passwordCfg.BcryptCost)
+	if err == nil {
+		err = s.rps.UpdatePassword(ctx, person.ID, hash)
+	}
+	if err == nil {
+		err = s.userCache.DeleteUserFromCache(ctx, person.ID)
+	}
+	if err != nil {
+		log.Errorf("service: can't rehash password of user %s - %v", person.ID, err)
+		return
+	}
+	person.Password = hash
+}
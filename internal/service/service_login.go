@@ -0,0 +1,92 @@
+// Package service : file contains brute-force protection of login
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"fmt"
+	"time"
+)
+
+// keys of login attempts, backoff lock is short pause after failure, lockout lock is set after too many failures
+func accountAttemptsKey(name string) string { return "account:" + name }
+func backoffLockKey(name string) string     { return "backoff:" + name }
+func lockoutLockKey(name string) string     { return "lockout:" + name }
+func ipAttemptsKey(ip string) string        { return "ip:" + ip }
+
+// checkLoginAllowed return error when account is locked or client makes attempts too often
+func (s *Service) checkLoginAllowed(ctx context.Context, name, ip string) error {
+	left, err := s.attempts.LockedFor(ctx, lockoutLockKey(name))
+	if err != nil {
+		return fmt.Errorf("service: can't check login attempts - %w", err)
+	}
+	if left > 0 {
+		return &model.RetryAfterError{After: left,
+			Err: fmt.Errorf("service: account is locked after too many failed logins: %w", model.ErrLocked)}
+	}
+	for _, key := range []string{backoffLockKey(name), ipAttemptsKey(ip)} {
+		left, err = s.attempts.LockedFor(ctx, key)
+		if err != nil {
+			return fmt.Errorf("service: can't check login attempts - %w", err)
+		}
+		if left > 0 {
+			return &model.RetryAfterError{After: left,
+				Err: fmt.Errorf("service: too many failed logins, try later: %w", model.ErrTooManyRequests)}
+		}
+	}
+	return nil
+}
+
+// loginFailed count failed login of account and client, lock them when limits are reached and return unauthorized error
+func (s *Service) loginFailed(ctx context.Context, name, ip string) error {
+	failures, err := s.attempts.Failed(ctx, accountAttemptsKey(name), s.loginCfg.Window)
+	if err != nil {
+		return fmt.Errorf("service: can't count login attempts - %w", err)
+	}
+	if failures >= s.loginCfg.MaxAttempts {
+		err = s.attempts.Lock(ctx, lockoutLockKey(name), s.loginCfg.LockoutTTL)
+	} else {
+		err = s.attempts.Lock(ctx, backoffLockKey(name), s.backoff(failures))
+	}
+	if err != nil {
+		return fmt.Errorf("service: can't lock login attempts - %w", err)
+	}
+	ipFailures, err := s.attempts.Failed(ctx, ipAttemptsKey(ip), s.loginCfg.Window)
+	if err != nil {
+		return fmt.Errorf("service: can't count login attempts - %w", err)
+	}
+	if ipFailures >= s.loginCfg.MaxIPAttempts {
+		err = s.attempts.Lock(ctx, ipAttemptsKey(ip), s.loginCfg.Window)
+		if err != nil {
+			return fmt.Errorf("service: can't lock login attempts - %w", err)
+		}
+	}
+	return fmt.Errorf("service: incorrect name or password: %w", model.ErrUnauthorized)
+}
+
+// backoff delay before next attempt after failures in a row, it doubles every time but doesnt exceed lockout
+func (s *Service) backoff(failures int64) time.Duration {
+	delay := s.loginCfg.BackoffBase
+	for i := int64(1); i < failures && delay < s.loginCfg.LockoutTTL; i++ {
+		delay *= 2
+	}
+	if delay > s.loginCfg.LockoutTTL {
+		delay = s.loginCfg.LockoutTTL
+	}
+	return delay
+}
+
+// UnlockAccount forget failed logins of user and remove his locks
+func (s *Service) UnlockAccount(ctx context.Context, id string) error {
+	person, err := s.rps.SelectByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: can't unlock account - %w", err)
+	}
+	for _, key := range []string{accountAttemptsKey(person.Name), backoffLockKey(person.Name), lockoutLockKey(person.Name)} {
+		err = s.attempts.Reset(ctx, key)
+		if err != nil {
+			return fmt.Errorf("service: can't unlock account - %w", err)
+		}
+	}
+	return nil
+}
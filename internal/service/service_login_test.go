@@ -0,0 +1,104 @@
+package service
+
+import (
+	"awesomeProject/internal/cache"
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testBackoffConfig = model.LoginConfig{MaxAttempts: 5, MaxIPAttempts: 8, Window: time.Minute, BackoffBase: time.Second,
+	LockoutTTL: 10 * time.Second}
+
+func TestService_backoff(t *testing.T) {
+	testData := []struct {
+		failures int64
+		delay    time.Duration
+	}{
+		{1, time.Second},
+		{2, 2 * time.Second},
+		{3, 4 * time.Second},
+		{4, 8 * time.Second},
+		{5, 10 * time.Second},
+		{40, 10 * time.Second},
+	}
+	s := &Service{loginCfg: testBackoffConfig}
+	for _, data := range testData {
+		require.Equal(t, data.delay, s.backoff(data.failures), "wrong delay after %d failures", data.failures)
+	}
+}
+
+func TestService_loginFailed(t *testing.T) {
+	testData := []struct {
+		failures int64
+		lockout  bool
+	}{
+		{1, false},
+		{4, false},
+		{5, true},
+		{6, true},
+	}
+	ctx := context.Background()
+	for _, data := range testData {
+		attempts := cache.NewMemoryLoginAttempts()
+		s := &Service{loginCfg: testBackoffConfig, attempts: attempts}
+		var err error
+		for i := int64(0); i < data.failures; i++ {
+			err = s.loginFailed(ctx, "user", "10.0.0.1")
+			require.True(t, errors.Is(err, model.ErrUnauthorized), "failed login isnt unauthorized: %v", err)
+		}
+		backoff, err := attempts.LockedFor(ctx, backoffLockKey("user"))
+		require.NoError(t, err)
+		require.Greater(t, backoff, time.Duration(0), "no backoff after %d failures", data.failures)
+		lockout, err := attempts.LockedFor(ctx, lockoutLockKey("user"))
+		require.NoError(t, err)
+		require.Equal(t, data.lockout, lockout > 0, "lockout after %d failures", data.failures)
+	}
+}
+
+func TestService_checkLoginAllowed(t *testing.T) {
+	testData := []struct {
+		name string
+		lock string
+		err  error
+	}{
+		{"free", "", nil},
+		{"paused", backoffLockKey("paused"), model.ErrTooManyRequests},
+		{"locked", lockoutLockKey("locked"), model.ErrLocked},
+		{"other", ipAttemptsKey("10.0.0.1"), model.ErrTooManyRequests},
+	}
+	ctx := context.Background()
+	for _, data := range testData {
+		attempts := cache.NewMemoryLoginAttempts()
+		s := &Service{loginCfg: testBackoffConfig, attempts: attempts}
+		if data.lock != "" {
+			require.NoError(t, attempts.Lock(ctx, data.lock, time.Minute))
+		}
+		err := s.checkLoginAllowed(ctx, data.name, "10.0.0.1")
+		if data.err == nil {
+			require.NoError(t, err, "login of %s is forbidden", data.name)
+			continue
+		}
+		require.True(t, errors.Is(err, data.err), "unexpected error %v for %s", err, data.name)
+		retry := &model.RetryAfterError{}
+		require.True(t, errors.As(err, &retry), "no retry time for %s", data.name)
+		require.Greater(t, retry.After, time.Duration(0))
+	}
+}
+
+func TestService_loginLockedByIP(t *testing.T) {
+	ctx := context.Background()
+	s := &Service{loginCfg: testBackoffConfig, attempts: cache.NewMemoryLoginAttempts()}
+	for i := int64(0); i < testBackoffConfig.MaxIPAttempts; i++ {
+		name := fmt.Sprintf("user%d", i) // accounts arent locked, only client reaches its limit
+		require.NoError(t, s.checkLoginAllowed(ctx, name, "10.0.0.2"), "client locked after %d failures", i)
+		_ = s.loginFailed(ctx, name, "10.0.0.2")
+	}
+	err := s.checkLoginAllowed(ctx, "somebody", "10.0.0.2")
+	require.True(t, errors.Is(err, model.ErrTooManyRequests), "client isnt locked: %v", err)
+}
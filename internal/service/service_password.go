@@ -0,0 +1,118 @@
+// Package service : file contains password change and reset logic
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/gommon/log"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// resetTokenSize count of random bytes in password reset token
+const resetTokenSize = 32
+
+// ChangePassword replace password of user after checking the old one, all his sessions are closed
+func (s *Service) ChangePassword(ctx context.Context, id, oldPassword, newPassword string) error {
+	person, err := s.rps.SelectByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: password change failed - %w", err)
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(person.Password), []byte(oldPassword))
+	if err != nil {
+		return fmt.Errorf("service: incorrect old password: %w", model.ErrForbidden)
+	}
+	hash, err := s.hashNewPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	return s.storePassword(ctx, id, hash)
+}
+
+// RequestPasswordReset send reset token to user, unknown name is not reported so names cant be enumerated
+func (s *Service) RequestPasswordReset(ctx context.Context, name string) error {
+	person, err := s.rps.SelectByName(ctx, name)
+	if errors.Is(err, model.ErrNotFound) {
+		log.Infof("service: password reset requested for unknown user %s", name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("service: password reset failed - %w", err)
+	}
+	raw := make([]byte, resetTokenSize)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return fmt.Errorf("service: can't generate reset token - %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	now := time.Now()
+	reset := model.PasswordReset{
+		ID:        uuid.New().String(),
+		UserID:    person.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: now,
+		ExpiresAt: now.Add(s.passwordCfg.ResetTTL),
+	}
+	err = s.rps.CreatePasswordReset(ctx, &reset)
+	if err != nil {
+		return fmt.Errorf("service: password reset failed - %w", err)
+	}
+	err = s.notifier.PasswordReset(ctx, person, token, reset.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("service: can't send reset token - %w", err)
+	}
+	return nil
+}
+
+// ResetPassword set new password by reset token, token can be used only once
+// and stays usable when new password is rejected
+func (s *Service) ResetPassword(ctx context.Context, token, newPassword string) error {
+	hash, err := s.hashNewPassword(newPassword)
+	if err != nil {
+		return err
+	}
+	reset, err := s.rps.UsePasswordReset(ctx, hashToken(token))
+	if errors.Is(err, model.ErrNotFound) {
+		return fmt.Errorf("service: reset token is invalid or expired: %w", model.ErrUnauthorized)
+	}
+	if err != nil {
+		return fmt.Errorf("service: password reset failed - %w", err)
+	}
+	return s.storePassword(ctx, reset.UserID, hash)
+}
+
+// hashNewPassword check new password by policy and hash it
+func (s *Service) hashNewPassword(password string) (string, error) {
+	err := s.policy.Check("newPassword", password)
+	if err != nil {
+		return "", err
+	}
+	hash, err := HashPasswordWithCost(password, s.passwordCfg.BcryptCost)
+	if err != nil {
+		return "", fmt.Errorf("service: can't hash password - %w", err)
+	}
+	return hash, nil
+}
+
+// storePassword store hash of new password and close all sessions of user
+func (s *Service) storePassword(ctx context.Context, id, hash string) error {
+	err := s.rps.UpdatePassword(ctx, id, hash)
+	if err != nil {
+		return fmt.Errorf("service: password change failed - %w", err)
+	}
+	err = s.userCache.DeleteUserFromCache(ctx, id)
+	if err != nil {
+		return fmt.Errorf("service: error while deleting user from cache, %w", err)
+	}
+	err = s.revokeAllSessions(ctx, id) // tokens issued with old password must stop working
+	if err != nil {
+		return fmt.Errorf("service: error while revoking user sessions, %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/password"
+	"awesomeProject/internal/repository"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_ResetPasswordRejected(t *testing.T) {
+	policy, err := password.NewPolicy(model.PasswordConfig{MinLength: 8, MinClasses: 3})
+	require.NoError(t, err, "bad password policy")
+	rps := &repository.PRepository{PPool: Pool}
+	deps := testDeps()
+	deps.Repository, deps.Policy = rps, policy
+	s := NewService(deps)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	id, err := s.Registration(ctx, &model.Person{Name: "reset-" + uuid.New().String(), Password: "Old password 1"})
+	require.NoError(t, err, "cannot register user")
+	token := uuid.New().String()
+	now := time.Now()
+	err = rps.CreatePasswordReset(ctx, &model.PasswordReset{ID: uuid.New().String(), UserID: id, TokenHash: hashToken(token),
+		CreatedAt: now, ExpiresAt: now.Add(time.Minute)})
+	require.NoError(t, err, "cannot create reset token")
+
+	err = s.ResetPassword(ctx, token, "short")
+	require.True(t, errors.Is(err, model.ErrValidation), "weak password is accepted: %v", err)
+	err = s.ResetPassword(ctx, token, "New password 2")
+	require.NoError(t, err, "token is burned by rejected password")
+	err = s.ResetPassword(ctx, token, "Other password 3")
+	require.True(t, errors.Is(err, model.ErrUnauthorized), "token is used twice: %v", err)
+}
@@ -0,0 +1,27 @@
+package service
+
+import (
+	"awesomeProject/internal/model"
+	"awesomeProject/internal/search"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SearchAdverts(t *testing.T) {
+	index := search.NewMemory()
+	index.Index(model.Advert{ID: "1", Title: "Flat", Address: "Minsk", Status: model.AdvertPublished})
+	index.Index(model.Advert{ID: "2", Title: "House", Address: "Brest", Status: model.AdvertPublished})
+	s := NewService(Deps{Searcher: index})
+
+	hits, err := s.SearchAdverts(context.Background(), "Minsk flat", 10)
+	require.NoError(t, err)
+	require.Len(t, hits, 1)
+	require.Equal(t, "1", hits[0].Advert.ID)
+	require.Equal(t, map[string]string{"title": "<mark>Flat</mark>", "address": "<mark>Minsk</mark>"}, hits[0].Highlights)
+
+	_, err = s.SearchAdverts(context.Background(), "?!", 10)
+	require.True(t, errors.Is(err, model.ErrValidation), "query without words is searched: %v", err)
+}
@@ -0,0 +1,168 @@
+// Package storage : file contains blob storage of uploaded files
+package storage
+
+import (
+	"awesomeProject/internal/model"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Storage keep blobs by slash separated keys like "adverts/<id>/<image>", other stores (s3, gcs) implement it
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New create storage by its kind from config
+func New(cfg model.StorageConfig) (Storage, error) {
+	switch cfg.Kind {
+	case "local":
+		return NewLocal(cfg.Dir)
+	}
+	return nil, fmt.Errorf("storage: unknown kind %q, expected local", cfg.Kind)
+}
+
+// checkKey reject keys which can point outside of storage
+func checkKey(key string) error {
+	if !fs.ValidPath(key) || key == "." {
+		return fmt.Errorf("storage: invalid key %q", key)
+	}
+	return nil
+}
+
+// notFound error about missing blob
+func notFound(key string) error {
+	return fmt.Errorf("file %s doesnt exist: %w", key, model.ErrNotFound)
+}
+
+// Local keep blobs as files in directory
+type Local struct {
+	dir string
+}
+
+// NewLocal create storage in directory, it is created if it doesnt exist
+func NewLocal(dir string) (*Local, error) {
+	err := os.MkdirAll(dir, 0o750)
+	if err != nil {
+		return nil, fmt.Errorf("storage: unable to create directory %s, %v", dir, err)
+	}
+	return &Local{dir: dir}, nil
+}
+
+// path file of key
+func (l *Local) path(key string) (string, error) {
+	if err := checkKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(l.dir, filepath.FromSlash(key)), nil
+}
+
+// Put write blob to temporary file and move it to its place, so readers never see half written file
+func (l *Local) Put(_ context.Context, key string, r io.Reader) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0o750)
+	if err != nil {
+		return fmt.Errorf("storage: unable to create directory for %s, %v", key, err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("storage: unable to create file for %s, %v", key, err)
+	}
+	_, err = io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("storage: unable to write %s, %v", key, err)
+	}
+	return nil
+}
+
+// Open open file of blob for reading
+func (l *Local) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, notFound(key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storage: unable to open %s, %v", key, err)
+	}
+	return f, nil
+}
+
+// Delete remove file of blob, missing blob is not an error
+func (l *Local) Delete(_ context.Context, key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	err = os.Remove(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: unable to delete %s, %v", key, err)
+	}
+	return nil
+}
+
+// Memory keep blobs in process memory, for tests
+type Memory struct {
+	mu    sync.RWMutex
+	blobs map[string][]byte
+}
+
+// NewMemory create empty in-memory storage
+func NewMemory() *Memory {
+	return &Memory{blobs: make(map[string][]byte)}
+}
+
+// Put read blob into memory
+func (m *Memory) Put(_ context.Context, key string, r io.Reader) error {
+	if err := checkKey(key); err != nil {
+		return err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("storage: unable to read %s, %v", key, err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.blobs[key] = data
+	return nil
+}
+
+// Open return reader of blob
+func (m *Memory) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	data, ok := m.blobs[key]
+	if !ok {
+		return nil, notFound(key)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete drop blob from memory
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.blobs, key)
+	return nil
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"awesomeProject/internal/model"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testStorage(t *testing.T, s Storage) {
+	ctx := context.Background()
+	require.NoError(t, s.Put(ctx, "adverts/1/image", strings.NewReader("picture")))
+	r, err := s.Open(ctx, "adverts/1/image")
+	require.NoError(t, err)
+	data, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "picture", string(data))
+
+	require.NoError(t, s.Put(ctx, "adverts/1/image", strings.NewReader("new picture")))
+	r, err = s.Open(ctx, "adverts/1/image")
+	require.NoError(t, err)
+	data, err = io.ReadAll(r)
+	require.NoError(t, err)
+	require.NoError(t, r.Close())
+	require.Equal(t, "new picture", string(data), "put must replace blob")
+
+	require.NoError(t, s.Delete(ctx, "adverts/1/image"))
+	require.NoError(t, s.Delete(ctx, "adverts/1/image"), "deleting missing blob is not an error")
+	_, err = s.Open(ctx, "adverts/1/image")
+	require.True(t, errors.Is(err, model.ErrNotFound))
+
+	for _, key := range []string{"", ".", "../secret", "/etc/passwd", "adverts/../../secret", "adverts//image"} {
+		require.Error(t, s.Put(ctx, key, strings.NewReader("x")), "key %q must be rejected", key)
+	}
+}
+
+func TestLocal(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewLocal(filepath.Join(dir, "uploads"))
+	require.NoError(t, err)
+	testStorage(t, s)
+	entries, err := os.ReadDir(filepath.Join(dir, "uploads", "adverts", "1"))
+	require.NoError(t, err)
+	require.Empty(t, entries, "temporary files must not be left")
+}
+
+func TestMemory(t *testing.T) {
+	testStorage(t, NewMemory())
+}
+
+func TestNew(t *testing.T) {
+	_, err := New(model.StorageConfig{Kind: "local", Dir: t.TempDir()})
+	require.NoError(t, err)
+	_, err = New(model.StorageConfig{Kind: "s3"})
+	require.Error(t, err)
+}